@@ -0,0 +1,228 @@
+package lettermint
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Address is an email address with an optional display name.
+//
+// Internationalized display names are encoded per RFC 2047 and
+// internationalized domain names are converted to punycode when the address
+// is sent.
+type Address struct {
+	// Name is the display name, e.g. "John Doe".
+	Name string
+
+	// Email is the address itself, e.g. "john@example.com".
+	Email string
+}
+
+// ParseAddress parses a single RFC 5322 address, e.g.
+// "John Doe <john@example.com>" or "john@example.com".
+func ParseAddress(address string) (Address, error) {
+	parsed, err := mail.ParseAddress(address)
+	if err != nil {
+		return Address{}, err
+	}
+	return Address{Name: parsed.Name, Email: parsed.Address}, nil
+}
+
+// String returns the address formatted for an email header. Use it for
+// addresses that have been validated; invalid parts are formatted as is.
+func (a Address) String() string {
+	formatted, err := a.format()
+	if err != nil {
+		return (&mail.Address{Name: a.Name, Address: a.Email}).String()
+	}
+	return formatted
+}
+
+// format validates the address and formats it for an email header.
+func (a Address) format() (string, error) {
+	if a.Email == "" {
+		return "", errors.New("email is required")
+	}
+	for _, r := range a.Name {
+		if r == utf8.RuneError || unicode.IsControl(r) {
+			return "", errors.New("display name contains invalid characters")
+		}
+	}
+
+	parsed, err := mail.ParseAddress("<" + a.Email + ">")
+	if err != nil {
+		return "", err
+	}
+	at := strings.LastIndexByte(parsed.Address, '@')
+	domain, err := domainToASCII(parsed.Address[at+1:])
+	if err != nil {
+		return "", err
+	}
+	return (&mail.Address{Name: a.Name, Address: parsed.Address[:at+1] + domain}).String(), nil
+}
+
+// AddressError is returned when an address passed to the email builder is
+// invalid.
+type AddressError struct {
+	// Field is the payload field of the address, e.g. "from" or "to.1".
+	Field string
+
+	// Address is the invalid address.
+	Address Address
+
+	// Err is the underlying error.
+	Err error
+}
+
+func (e *AddressError) Error() string {
+	return fmt.Sprintf("lettermint: invalid %s address %q: %v", e.Field, e.Address.Email, e.Err)
+}
+
+func (e *AddressError) Unwrap() error {
+	return e.Err
+}
+
+// formatAddresses validates and formats addresses for the given field,
+// numbering them from offset.
+func formatAddresses(field string, offset int, addresses []Address) ([]string, error) {
+	formatted := make([]string, 0, len(addresses))
+	for i, address := range addresses {
+		value, err := address.format()
+		if err != nil {
+			return nil, &AddressError{Field: field + "." + strconv.Itoa(offset+i), Address: address, Err: err}
+		}
+		formatted = append(formatted, value)
+	}
+	return formatted, nil
+}
+
+// domainToASCII converts an internationalized domain name to its ASCII
+// form, encoding non-ASCII labels as punycode (RFC 3492).
+func domainToASCII(domain string) (string, error) {
+	if isASCII(domain) {
+		return domain, nil
+	}
+	labels := strings.Split(domain, ".")
+	for i, label := range labels {
+		if isASCII(label) {
+			continue
+		}
+		encoded, err := punycode(strings.ToLower(label))
+		if err != nil {
+			return "", fmt.Errorf("invalid domain %q: %w", domain, err)
+		}
+		labels[i] = "xn--" + encoded
+		if len(labels[i]) > 63 {
+			return "", fmt.Errorf("invalid domain %q: label is too long", domain)
+		}
+	}
+	return strings.Join(labels, "."), nil
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+// Punycode parameters from RFC 3492, section 5.
+const (
+	punycodeBase        = 36
+	punycodeTMin        = 1
+	punycodeTMax        = 26
+	punycodeSkew        = 38
+	punycodeDamp        = 700
+	punycodeInitialBias = 72
+	punycodeInitialN    = 128
+)
+
+// punycode encodes a label per RFC 3492, section 6.3.
+func punycode(label string) (string, error) {
+	runes := []rune(label)
+	var out []byte
+	for _, r := range runes {
+		if r < utf8.RuneSelf {
+			out = append(out, byte(r))
+		}
+	}
+	basic := len(out)
+	handled := basic
+	if basic > 0 {
+		out = append(out, '-')
+	}
+
+	n, delta, bias := rune(punycodeInitialN), 0, punycodeInitialBias
+	for handled < len(runes) {
+		next := rune(utf8.MaxRune + 1)
+		for _, r := range runes {
+			if r >= n && r < next {
+				next = r
+			}
+		}
+		if int(next-n) > (1<<31-1-delta)/(handled+1) {
+			return "", errors.New("punycode overflow")
+		}
+		delta += int(next-n) * (handled + 1)
+		n = next
+
+		for _, r := range runes {
+			if r < n {
+				delta++
+			}
+			if r != n {
+				continue
+			}
+			q := delta
+			for k := punycodeBase; ; k += punycodeBase {
+				t := k - bias
+				if t < punycodeTMin {
+					t = punycodeTMin
+				} else if t > punycodeTMax {
+					t = punycodeTMax
+				}
+				if q < t {
+					break
+				}
+				out = append(out, punycodeDigit(t+(q-t)%(punycodeBase-t)))
+				q = (q - t) / (punycodeBase - t)
+			}
+			out = append(out, punycodeDigit(q))
+			bias = punycodeAdapt(delta, handled+1, handled == basic)
+			delta = 0
+			handled++
+		}
+		delta++
+		n++
+	}
+	return string(out), nil
+}
+
+func punycodeAdapt(delta, points int, first bool) int {
+	if first {
+		delta /= punycodeDamp
+	} else {
+		delta /= 2
+	}
+	delta += delta / points
+	k := 0
+	for delta > ((punycodeBase-punycodeTMin)*punycodeTMax)/2 {
+		delta /= punycodeBase - punycodeTMin
+		k += punycodeBase
+	}
+	return k + (punycodeBase-punycodeTMin+1)*delta/(delta+punycodeSkew)
+}
+
+func punycodeDigit(d int) byte {
+	if d < 26 {
+		return byte('a' + d)
+	}
+	return byte('0' + d - 26)
+}
@@ -0,0 +1,140 @@
+package lettermint
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestParseAddress(t *testing.T) {
+	tests := []struct {
+		input string
+		want  Address
+	}{
+		{"john@example.com", Address{Email: "john@example.com"}},
+		{"John Doe <john@example.com>", Address{Name: "John Doe", Email: "john@example.com"}},
+		{`"Doe, John" <john@example.com>`, Address{Name: "Doe, John", Email: "john@example.com"}},
+		{"=?utf-8?q?J=C3=BCrgen?= <jurgen@example.com>", Address{Name: "Jürgen", Email: "jurgen@example.com"}},
+	}
+	for _, tt := range tests {
+		got, err := ParseAddress(tt.input)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseAddress(%q) = %+v, %v, want %+v", tt.input, got, err, tt.want)
+		}
+	}
+
+	if _, err := ParseAddress("John <john@>"); err == nil {
+		t.Error("ParseAddress() error = nil for an invalid address")
+	}
+}
+
+func TestAddress_String(t *testing.T) {
+	tests := []struct {
+		address Address
+		want    string
+	}{
+		{Address{Email: "john@example.com"}, "<john@example.com>"},
+		{Address{Name: "John Doe", Email: "john@example.com"}, `"John Doe" <john@example.com>`},
+		{Address{Name: `Doe, "JD" John`, Email: "john@example.com"}, `"Doe, \"JD\" John" <john@example.com>`},
+		{Address{Name: "Jürgen", Email: "jurgen@example.com"}, "=?utf-8?q?J=C3=BCrgen?= <jurgen@example.com>"},
+		{Address{Email: "info@bücher.de"}, "<info@xn--bcher-kva.de>"},
+		{Address{Email: "post@MÜNCHEN.example"}, "<post@xn--mnchen-3ya.example>"},
+	}
+	for _, tt := range tests {
+		if got := tt.address.String(); got != tt.want {
+			t.Errorf("%+v.String() = %q, want %q", tt.address, got, tt.want)
+		}
+	}
+}
+
+func TestPunycode(t *testing.T) {
+	// Samples from RFC 3492, section 7.1.
+	tests := map[string]string{
+		"ليهمابتكلموشعربي؟": "egbpdaj6bu4bxfgehfvwxn",
+		"他们为什么不说中文":         "ihqwcrb4cv8a8dqg056pqjye",
+		"bücher":            "bcher-kva",
+		"3年b組金八先生":          "3b-ww4c5e180e575a65lsy2b",
+	}
+	for input, want := range tests {
+		if got, err := punycode(input); err != nil || got != want {
+			t.Errorf("punycode(%q) = %q, %v, want %q", input, got, err, want)
+		}
+	}
+}
+
+func TestEmailBuilder_Addresses(t *testing.T) {
+	var got emailPayload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		_, _ = w.Write([]byte(`{"message_id":"msg_1","status":"pending"}`))
+	}))
+	defer server.Close()
+
+	client, _ := New("test-token", WithBaseURL(server.URL))
+	_, err := client.Email(context.Background()).
+		FromAddress(Address{Name: "Acme Support", Email: "support@acme.example"}).
+		To("raw@example.com").
+		ToAddress(Address{Name: "Jürgen", Email: "jurgen@bücher.de"}).
+		CCAddress(Address{Email: "cc@example.com"}).
+		BCCAddress(Address{Email: "bcc@example.com"}).
+		ReplyToAddress(Address{Name: "Doe, John", Email: "john@example.com"}).
+		Subject("Hello").
+		Text("Body").
+		Send()
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	want := emailPayload{
+		From:    `"Acme Support" <support@acme.example>`,
+		To:      []string{"raw@example.com", "=?utf-8?q?J=C3=BCrgen?= <jurgen@xn--bcher-kva.de>"},
+		CC:      []string{"<cc@example.com>"},
+		BCC:     []string{"<bcc@example.com>"},
+		ReplyTo: []string{`"Doe, John" <john@example.com>`},
+		Subject: "Hello",
+		Text:    "Body",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("payload = %+v, want %+v", got, want)
+	}
+}
+
+func TestEmailBuilder_InvalidAddress(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+	}))
+	defer server.Close()
+
+	client, _ := New("test-token", WithBaseURL(server.URL))
+	email := func() *EmailBuilder {
+		return client.Email(context.Background()).
+			From("sender@example.com").
+			Subject("Hello").
+			Text("Body")
+	}
+
+	tests := []struct {
+		builder *EmailBuilder
+		field   string
+	}{
+		{email().FromAddress(Address{Email: "not-an-address"}).To("to@example.com"), "from"},
+		{email().To("first@example.com").ToAddress(Address{Email: "second@example.com"}, Address{Email: "third@"}), "to.2"},
+		{email().To("to@example.com").CCAddress(Address{Name: "Bad\r\nBcc: x@example.com", Email: "cc@example.com"}), "cc.0"},
+		{email().To("to@example.com").ReplyToAddress(Address{}), "reply_to.0"},
+	}
+	for _, tt := range tests {
+		_, err := tt.builder.Send()
+		var addressErr *AddressError
+		if !errors.Is(err, ErrInvalidRequest) || !errors.As(err, &addressErr) || addressErr.Field != tt.field {
+			t.Errorf("Send() error = %v, want invalid %s address", err, tt.field)
+		}
+	}
+	if requests != 0 {
+		t.Fatalf("requests = %d, want none", requests)
+	}
+}
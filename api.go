@@ -24,7 +24,20 @@ func NewAPI(apiToken string, opts ...Option) (*APIClient, error) {
 	if err != nil {
 		return nil, err
 	}
+	return newAPIClient(client), nil
+}
+
+// NewAPIWithTokenSource creates a new Team API client that obtains its API
+// token from source before every request.
+func NewAPIWithTokenSource(source TokenSource, opts ...Option) (*APIClient, error) {
+	client, err := newTokenSourceClient(source, authSchemeBearer, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return newAPIClient(client), nil
+}
 
+func newAPIClient(client *Client) *APIClient {
 	return &APIClient{
 		client:       client,
 		Domains:      &DomainsService{client: client},
@@ -35,26 +48,77 @@ func NewAPI(apiToken string, opts ...Option) (*APIClient, error) {
 		Suppressions: &SuppressionsService{client: client},
 		Team:         &TeamService{client: client},
 		Webhooks:     &WebhooksService{client: client},
-	}, nil
+	}
 }
 
-func (api *APIClient) Ping(ctx context.Context) (string, error) {
-	return rawPing(api.client.doRaw(ctx, http.MethodGet, "/ping", nil))
+func (api *APIClient) Ping(ctx context.Context, opts ...CallOption) (string, error) {
+	return rawPing(api.client.doRaw(ctx, operation("Ping"), http.MethodGet, "/ping", nil, opts...))
 }
 
-func (api *APIClient) BlockedFileTypes(ctx context.Context) (BlockedFileTypesResponse, error) {
+func (api *APIClient) BlockedFileTypes(ctx context.Context, opts ...CallOption) (BlockedFileTypesResponse, error) {
 	var out BlockedFileTypesResponse
-	err := api.client.doJSON(ctx, http.MethodGet, "/blocked-file-types", nil, nil, &out)
-	return out, err
+	err := api.client.doJSON(ctx, operation("BlockedFileTypes"), http.MethodGet, "/blocked-file-types", nil, nil, &out, opts...)
+	return out, err
+}
+
+// Do calls an API endpoint that has no dedicated method yet.
+//
+// The path is relative to the base URL, e.g. "/domains". A non-nil body is
+// encoded as JSON, and a JSON response is decoded into out unless out is nil.
+// The request uses the same authentication, retries, middleware, observers
+// and error handling as all other methods; error responses are returned as
+// *APIError.
+//
+// Example:
+//
+//	var out struct {
+//	    Data []map[string]any `json:"data"`
+//	}
+//	err := api.Do(ctx, http.MethodGet, "/new-endpoint", map[string]string{"page[size]": "10"}, nil, &out)
+func (api *APIClient) Do(ctx context.Context, method, path string, query map[string]string, body, out interface{}, opts ...CallOption) error {
+	return api.client.doJSON(ctx, operation("Do"), method, path, query, body, out, opts...)
+}
+
+// DoRaw is like Do, but returns the successful HTTP response with an unread
+// body instead of decoding it. The caller must close the response body.
+//
+// Reading more than the response size limit of the call (see
+// WithMaxResponseBytes) from the body fails with ErrResponseTooLarge.
+func (api *APIClient) DoRaw(ctx context.Context, method, path string, query map[string]string, body interface{}, opts ...CallOption) (*http.Response, error) {
+	data, err := requestBody(body)
+	if err != nil {
+		return nil, err
+	}
+	return api.client.doResponse(ctx, operation("Do"), method, path, query, data, 0, opts)
 }
 
-func (c *Client) Ping(ctx context.Context) (string, error) {
-	return rawPing(c.doRaw(ctx, http.MethodGet, "/ping", nil))
+func (c *Client) Ping(ctx context.Context, opts ...CallOption) (string, error) {
+	return rawPing(c.doRaw(ctx, operation("Ping"), http.MethodGet, "/ping", nil, opts...))
 }
 
-func (c *Client) SendBatch(ctx context.Context, payload SendBatchMailRequest) (SendBatchEmailResponse, error) {
+func (c *Client) SendBatch(ctx context.Context, payload SendBatchMailRequest, opts ...CallOption) (SendBatchEmailResponse, error) {
 	var out SendBatchEmailResponse
-	err := c.doJSON(ctx, http.MethodPost, "/send/batch", nil, payload, &out)
+	ctx, callHeader, cancel := applyCallOptions(ctx, opts)
+	defer cancel()
+	header, err := c.idempotencyHeader(ctx, callHeader.Get("Idempotency-Key"), payload)
+	if err != nil {
+		return out, err
+	}
+	for key, values := range callHeader {
+		header[key] = values
+	}
+	body, err := requestBody(payload)
+	if err != nil {
+		return out, err
+	}
+	op := operation("Email.SendBatch")
+	err = c.observe(ctx, op, http.MethodPost, "/send/batch", func(ctx context.Context) error {
+		resp, err := c.do(ctx, op, http.MethodPost, "/send/batch", nil, bytesBody(body), header)
+		if err != nil {
+			return err
+		}
+		return c.decodeResponse(ctx, resp, &out)
+	})
 	return out, err
 }
 
@@ -67,267 +131,267 @@ type SuppressionsService struct{ client *Client }
 type TeamService struct{ client *Client }
 type WebhooksService struct{ client *Client }
 
-func (s *DomainsService) List(ctx context.Context, query map[string]string) (DomainIndexResponse, error) {
+func (s *DomainsService) List(ctx context.Context, query map[string]string, opts ...CallOption) (DomainIndexResponse, error) {
 	var out DomainIndexResponse
-	err := s.client.doJSON(ctx, http.MethodGet, "/domains", query, nil, &out)
+	err := s.client.doJSON(ctx, operation("Domains.List"), http.MethodGet, "/domains", query, nil, &out, opts...)
 	return out, err
 }
 
-func (s *DomainsService) Create(ctx context.Context, payload DomainStoreRequest) (DomainStoreResponse, error) {
+func (s *DomainsService) Create(ctx context.Context, payload DomainStoreRequest, opts ...CallOption) (DomainStoreResponse, error) {
 	var out DomainStoreResponse
-	err := s.client.doJSON(ctx, http.MethodPost, "/domains", nil, payload, &out)
+	err := s.client.doJSON(ctx, operation("Domains.Create"), http.MethodPost, "/domains", nil, payload, &out, opts...)
 	return out, err
 }
 
-func (s *DomainsService) Retrieve(ctx context.Context, domainID string) (DomainShowResponse, error) {
+func (s *DomainsService) Retrieve(ctx context.Context, domainID string, opts ...CallOption) (DomainShowResponse, error) {
 	var out DomainShowResponse
-	err := s.client.doJSON(ctx, http.MethodGet, "/domains/"+segment(domainID), nil, nil, &out)
+	err := s.client.doJSON(ctx, operation("Domains.Retrieve"), http.MethodGet, "/domains/"+segment(domainID), nil, nil, &out, opts...)
 	return out, err
 }
 
-func (s *DomainsService) Delete(ctx context.Context, domainID string) (DomainDestroyResponse, error) {
+func (s *DomainsService) Delete(ctx context.Context, domainID string, opts ...CallOption) (DomainDestroyResponse, error) {
 	var out DomainDestroyResponse
-	err := s.client.doJSON(ctx, http.MethodDelete, "/domains/"+segment(domainID), nil, nil, &out)
+	err := s.client.doJSON(ctx, operation("Domains.Delete"), http.MethodDelete, "/domains/"+segment(domainID), nil, nil, &out, opts...)
 	return out, err
 }
 
-func (s *DomainsService) VerifyDNSRecords(ctx context.Context, domainID string) (DomainVerifyDNSRecordsResponse, error) {
+func (s *DomainsService) VerifyDNSRecords(ctx context.Context, domainID string, opts ...CallOption) (DomainVerifyDNSRecordsResponse, error) {
 	var out DomainVerifyDNSRecordsResponse
-	err := s.client.doJSON(ctx, http.MethodPost, "/domains/"+segment(domainID)+"/dns-records/verify", nil, nil, &out)
+	err := s.client.doJSON(ctx, operation("Domains.VerifyDNSRecords"), http.MethodPost, "/domains/"+segment(domainID)+"/dns-records/verify", nil, nil, &out, opts...)
 	return out, err
 }
 
-func (s *DomainsService) VerifyDNSRecord(ctx context.Context, domainID, recordID string) (DomainVerifySpecificDNSRecordResponse, error) {
+func (s *DomainsService) VerifyDNSRecord(ctx context.Context, domainID, recordID string, opts ...CallOption) (DomainVerifySpecificDNSRecordResponse, error) {
 	var out DomainVerifySpecificDNSRecordResponse
-	err := s.client.doJSON(ctx, http.MethodPost, "/domains/"+segment(domainID)+"/dns-records/"+segment(recordID)+"/verify", nil, nil, &out)
+	err := s.client.doJSON(ctx, operation("Domains.VerifyDNSRecord"), http.MethodPost, "/domains/"+segment(domainID)+"/dns-records/"+segment(recordID)+"/verify", nil, nil, &out, opts...)
 	return out, err
 }
 
-func (s *DomainsService) UpdateProjects(ctx context.Context, domainID string, payload DomainUpdateProjectsRequest) (DomainUpdateProjectsResponse, error) {
+func (s *DomainsService) UpdateProjects(ctx context.Context, domainID string, payload DomainUpdateProjectsRequest, opts ...CallOption) (DomainUpdateProjectsResponse, error) {
 	var out DomainUpdateProjectsResponse
-	err := s.client.doJSON(ctx, http.MethodPut, "/domains/"+segment(domainID)+"/projects", nil, payload, &out)
+	err := s.client.doJSON(ctx, operation("Domains.UpdateProjects"), http.MethodPut, "/domains/"+segment(domainID)+"/projects", nil, payload, &out, opts...)
 	return out, err
 }
 
-func (s *MessagesService) List(ctx context.Context, query map[string]string) (MessageIndexResponse, error) {
+func (s *MessagesService) List(ctx context.Context, query map[string]string, opts ...CallOption) (MessageIndexResponse, error) {
 	var out MessageIndexResponse
-	err := s.client.doJSON(ctx, http.MethodGet, "/messages", query, nil, &out)
+	err := s.client.doJSON(ctx, operation("Messages.List"), http.MethodGet, "/messages", query, nil, &out, opts...)
 	return out, err
 }
 
-func (s *MessagesService) Retrieve(ctx context.Context, messageID string) (MessageShowResponse, error) {
+func (s *MessagesService) Retrieve(ctx context.Context, messageID string, opts ...CallOption) (MessageShowResponse, error) {
 	var out MessageShowResponse
-	err := s.client.doJSON(ctx, http.MethodGet, "/messages/"+segment(messageID), nil, nil, &out)
+	err := s.client.doJSON(ctx, messageOperation("Messages.Retrieve", messageID), http.MethodGet, "/messages/"+segment(messageID), nil, nil, &out, opts...)
 	return out, err
 }
 
-func (s *MessagesService) Events(ctx context.Context, messageID string) (MessageEventsResponse, error) {
+func (s *MessagesService) Events(ctx context.Context, messageID string, opts ...CallOption) (MessageEventsResponse, error) {
 	var out MessageEventsResponse
-	err := s.client.doJSON(ctx, http.MethodGet, "/messages/"+segment(messageID)+"/events", nil, nil, &out)
+	err := s.client.doJSON(ctx, messageOperation("Messages.Events", messageID), http.MethodGet, "/messages/"+segment(messageID)+"/events", nil, nil, &out, opts...)
 	return out, err
 }
 
-func (s *MessagesService) Source(ctx context.Context, messageID string) (string, error) {
-	return s.client.doRaw(ctx, http.MethodGet, "/messages/"+segment(messageID)+"/source", nil)
+func (s *MessagesService) Source(ctx context.Context, messageID string, opts ...CallOption) (string, error) {
+	return s.client.doRaw(ctx, messageOperation("Messages.Source", messageID), http.MethodGet, "/messages/"+segment(messageID)+"/source", nil, opts...)
 }
 
-func (s *MessagesService) HTML(ctx context.Context, messageID string) (string, error) {
-	return s.client.doRaw(ctx, http.MethodGet, "/messages/"+segment(messageID)+"/html", nil)
+func (s *MessagesService) HTML(ctx context.Context, messageID string, opts ...CallOption) (string, error) {
+	return s.client.doRaw(ctx, messageOperation("Messages.HTML", messageID), http.MethodGet, "/messages/"+segment(messageID)+"/html", nil, opts...)
 }
 
-func (s *MessagesService) Text(ctx context.Context, messageID string) (string, error) {
-	return s.client.doRaw(ctx, http.MethodGet, "/messages/"+segment(messageID)+"/text", nil)
+func (s *MessagesService) Text(ctx context.Context, messageID string, opts ...CallOption) (string, error) {
+	return s.client.doRaw(ctx, messageOperation("Messages.Text", messageID), http.MethodGet, "/messages/"+segment(messageID)+"/text", nil, opts...)
 }
 
-func (s *ProjectsService) List(ctx context.Context, query map[string]string) (ProjectIndexResponse, error) {
+func (s *ProjectsService) List(ctx context.Context, query map[string]string, opts ...CallOption) (ProjectIndexResponse, error) {
 	var out ProjectIndexResponse
-	err := s.client.doJSON(ctx, http.MethodGet, "/projects", query, nil, &out)
+	err := s.client.doJSON(ctx, operation("Projects.List"), http.MethodGet, "/projects", query, nil, &out, opts...)
 	return out, err
 }
 
-func (s *ProjectsService) Create(ctx context.Context, payload ProjectStoreRequest) (ProjectStoreResponse, error) {
+func (s *ProjectsService) Create(ctx context.Context, payload ProjectStoreRequest, opts ...CallOption) (ProjectStoreResponse, error) {
 	var out ProjectStoreResponse
-	err := s.client.doJSON(ctx, http.MethodPost, "/projects", nil, payload, &out)
+	err := s.client.doJSON(ctx, operation("Projects.Create"), http.MethodPost, "/projects", nil, payload, &out, opts...)
 	return out, err
 }
 
-func (s *ProjectsService) Retrieve(ctx context.Context, projectID string) (ProjectShowResponse, error) {
+func (s *ProjectsService) Retrieve(ctx context.Context, projectID string, opts ...CallOption) (ProjectShowResponse, error) {
 	var out ProjectShowResponse
-	err := s.client.doJSON(ctx, http.MethodGet, "/projects/"+segment(projectID), nil, nil, &out)
+	err := s.client.doJSON(ctx, operation("Projects.Retrieve"), http.MethodGet, "/projects/"+segment(projectID), nil, nil, &out, opts...)
 	return out, err
 }
 
-func (s *ProjectsService) Update(ctx context.Context, projectID string, payload ProjectUpdateRequest) (ProjectUpdateResponse, error) {
+func (s *ProjectsService) Update(ctx context.Context, projectID string, payload ProjectUpdateRequest, opts ...CallOption) (ProjectUpdateResponse, error) {
 	var out ProjectUpdateResponse
-	err := s.client.doJSON(ctx, http.MethodPut, "/projects/"+segment(projectID), nil, payload, &out)
+	err := s.client.doJSON(ctx, operation("Projects.Update"), http.MethodPut, "/projects/"+segment(projectID), nil, payload, &out, opts...)
 	return out, err
 }
 
-func (s *ProjectsService) Delete(ctx context.Context, projectID string) (ProjectDestroyResponse, error) {
+func (s *ProjectsService) Delete(ctx context.Context, projectID string, opts ...CallOption) (ProjectDestroyResponse, error) {
 	var out ProjectDestroyResponse
-	err := s.client.doJSON(ctx, http.MethodDelete, "/projects/"+segment(projectID), nil, nil, &out)
+	err := s.client.doJSON(ctx, operation("Projects.Delete"), http.MethodDelete, "/projects/"+segment(projectID), nil, nil, &out, opts...)
 	return out, err
 }
 
-func (s *ProjectsService) RotateToken(ctx context.Context, projectID string) (ProjectRotateTokenResponse, error) {
+func (s *ProjectsService) RotateToken(ctx context.Context, projectID string, opts ...CallOption) (ProjectRotateTokenResponse, error) {
 	var out ProjectRotateTokenResponse
-	err := s.client.doJSON(ctx, http.MethodPost, "/projects/"+segment(projectID)+"/rotate-token", nil, nil, &out)
+	err := s.client.doJSON(ctx, operation("Projects.RotateToken"), http.MethodPost, "/projects/"+segment(projectID)+"/rotate-token", nil, nil, &out, opts...)
 	return out, err
 }
 
-func (s *ProjectsService) UpdateMembers(ctx context.Context, projectID string, payload ProjectUpdateMembersRequest) (ProjectUpdateMembersResponse, error) {
+func (s *ProjectsService) UpdateMembers(ctx context.Context, projectID string, payload ProjectUpdateMembersRequest, opts ...CallOption) (ProjectUpdateMembersResponse, error) {
 	var out ProjectUpdateMembersResponse
-	err := s.client.doJSON(ctx, http.MethodPut, "/projects/"+segment(projectID)+"/members", nil, payload, &out)
+	err := s.client.doJSON(ctx, operation("Projects.UpdateMembers"), http.MethodPut, "/projects/"+segment(projectID)+"/members", nil, payload, &out, opts...)
 	return out, err
 }
 
-func (s *ProjectsService) AddMember(ctx context.Context, projectID, teamMemberID string) (ProjectAddMemberResponse, error) {
+func (s *ProjectsService) AddMember(ctx context.Context, projectID, teamMemberID string, opts ...CallOption) (ProjectAddMemberResponse, error) {
 	var out ProjectAddMemberResponse
-	err := s.client.doJSON(ctx, http.MethodPost, "/projects/"+segment(projectID)+"/members/"+segment(teamMemberID), nil, nil, &out)
+	err := s.client.doJSON(ctx, operation("Projects.AddMember"), http.MethodPost, "/projects/"+segment(projectID)+"/members/"+segment(teamMemberID), nil, nil, &out, opts...)
 	return out, err
 }
 
-func (s *ProjectsService) RemoveMember(ctx context.Context, projectID, teamMemberID string) (ProjectRemoveMemberResponse, error) {
+func (s *ProjectsService) RemoveMember(ctx context.Context, projectID, teamMemberID string, opts ...CallOption) (ProjectRemoveMemberResponse, error) {
 	var out ProjectRemoveMemberResponse
-	err := s.client.doJSON(ctx, http.MethodDelete, "/projects/"+segment(projectID)+"/members/"+segment(teamMemberID), nil, nil, &out)
+	err := s.client.doJSON(ctx, operation("Projects.RemoveMember"), http.MethodDelete, "/projects/"+segment(projectID)+"/members/"+segment(teamMemberID), nil, nil, &out, opts...)
 	return out, err
 }
 
-func (s *ProjectsService) Routes(ctx context.Context, projectID string, query map[string]string) (RouteIndexResponse, error) {
+func (s *ProjectsService) Routes(ctx context.Context, projectID string, query map[string]string, opts ...CallOption) (RouteIndexResponse, error) {
 	var out RouteIndexResponse
-	err := s.client.doJSON(ctx, http.MethodGet, "/projects/"+segment(projectID)+"/routes", query, nil, &out)
+	err := s.client.doJSON(ctx, operation("Projects.Routes"), http.MethodGet, "/projects/"+segment(projectID)+"/routes", query, nil, &out, opts...)
 	return out, err
 }
 
-func (s *ProjectsService) CreateRoute(ctx context.Context, projectID string, payload RouteStoreRequest) (RouteStoreResponse, error) {
+func (s *ProjectsService) CreateRoute(ctx context.Context, projectID string, payload RouteStoreRequest, opts ...CallOption) (RouteStoreResponse, error) {
 	var out RouteStoreResponse
-	err := s.client.doJSON(ctx, http.MethodPost, "/projects/"+segment(projectID)+"/routes", nil, payload, &out)
+	err := s.client.doJSON(ctx, operation("Projects.CreateRoute"), http.MethodPost, "/projects/"+segment(projectID)+"/routes", nil, payload, &out, opts...)
 	return out, err
 }
 
-func (s *RoutesService) Retrieve(ctx context.Context, routeID string) (RouteShowResponse, error) {
+func (s *RoutesService) Retrieve(ctx context.Context, routeID string, opts ...CallOption) (RouteShowResponse, error) {
 	var out RouteShowResponse
-	err := s.client.doJSON(ctx, http.MethodGet, "/routes/"+segment(routeID), nil, nil, &out)
+	err := s.client.doJSON(ctx, operation("Routes.Retrieve"), http.MethodGet, "/routes/"+segment(routeID), nil, nil, &out, opts...)
 	return out, err
 }
 
-func (s *RoutesService) Update(ctx context.Context, routeID string, payload RouteUpdateRequest) (RouteUpdateResponse, error) {
+func (s *RoutesService) Update(ctx context.Context, routeID string, payload RouteUpdateRequest, opts ...CallOption) (RouteUpdateResponse, error) {
 	var out RouteUpdateResponse
-	err := s.client.doJSON(ctx, http.MethodPut, "/routes/"+segment(routeID), nil, payload, &out)
+	err := s.client.doJSON(ctx, operation("Routes.Update"), http.MethodPut, "/routes/"+segment(routeID), nil, payload, &out, opts...)
 	return out, err
 }
 
-func (s *RoutesService) Delete(ctx context.Context, routeID string) (RouteDestroyResponse, error) {
+func (s *RoutesService) Delete(ctx context.Context, routeID string, opts ...CallOption) (RouteDestroyResponse, error) {
 	var out RouteDestroyResponse
-	err := s.client.doJSON(ctx, http.MethodDelete, "/routes/"+segment(routeID), nil, nil, &out)
+	err := s.client.doJSON(ctx, operation("Routes.Delete"), http.MethodDelete, "/routes/"+segment(routeID), nil, nil, &out, opts...)
 	return out, err
 }
 
-func (s *RoutesService) VerifyInboundDomain(ctx context.Context, routeID string) (RouteVerifyInboundDomainResponse, error) {
+func (s *RoutesService) VerifyInboundDomain(ctx context.Context, routeID string, opts ...CallOption) (RouteVerifyInboundDomainResponse, error) {
 	var out RouteVerifyInboundDomainResponse
-	err := s.client.doJSON(ctx, http.MethodPost, "/routes/"+segment(routeID)+"/verify-inbound-domain", nil, nil, &out)
+	err := s.client.doJSON(ctx, operation("Routes.VerifyInboundDomain"), http.MethodPost, "/routes/"+segment(routeID)+"/verify-inbound-domain", nil, nil, &out, opts...)
 	return out, err
 }
 
-func (s *StatsService) Retrieve(ctx context.Context, query map[string]string) (StatsIndexResponse, error) {
+func (s *StatsService) Retrieve(ctx context.Context, query map[string]string, opts ...CallOption) (StatsIndexResponse, error) {
 	var out StatsIndexResponse
-	err := s.client.doJSON(ctx, http.MethodGet, "/stats", query, nil, &out)
+	err := s.client.doJSON(ctx, operation("Stats.Retrieve"), http.MethodGet, "/stats", query, nil, &out, opts...)
 	return out, err
 }
 
-func (s *SuppressionsService) List(ctx context.Context, query map[string]string) (SuppressionIndexResponse, error) {
+func (s *SuppressionsService) List(ctx context.Context, query map[string]string, opts ...CallOption) (SuppressionIndexResponse, error) {
 	var out SuppressionIndexResponse
-	err := s.client.doJSON(ctx, http.MethodGet, "/suppressions", query, nil, &out)
+	err := s.client.doJSON(ctx, operation("Suppressions.List"), http.MethodGet, "/suppressions", query, nil, &out, opts...)
 	return out, err
 }
 
-func (s *SuppressionsService) Create(ctx context.Context, payload SuppressionStoreRequest) (SuppressionStoreResponse, error) {
+func (s *SuppressionsService) Create(ctx context.Context, payload SuppressionStoreRequest, opts ...CallOption) (SuppressionStoreResponse, error) {
 	var out SuppressionStoreResponse
-	err := s.client.doJSON(ctx, http.MethodPost, "/suppressions", nil, payload, &out)
+	err := s.client.doJSON(ctx, operation("Suppressions.Create"), http.MethodPost, "/suppressions", nil, payload, &out, opts...)
 	return out, err
 }
 
-func (s *SuppressionsService) Delete(ctx context.Context, suppressionID string) (SuppressionDestroyResponse, error) {
+func (s *SuppressionsService) Delete(ctx context.Context, suppressionID string, opts ...CallOption) (SuppressionDestroyResponse, error) {
 	var out SuppressionDestroyResponse
-	err := s.client.doJSON(ctx, http.MethodDelete, "/suppressions/"+segment(suppressionID), nil, nil, &out)
+	err := s.client.doJSON(ctx, operation("Suppressions.Delete"), http.MethodDelete, "/suppressions/"+segment(suppressionID), nil, nil, &out, opts...)
 	return out, err
 }
 
-func (s *TeamService) Retrieve(ctx context.Context) (TeamShowResponse, error) {
+func (s *TeamService) Retrieve(ctx context.Context, opts ...CallOption) (TeamShowResponse, error) {
 	var out TeamShowResponse
-	err := s.client.doJSON(ctx, http.MethodGet, "/team", nil, nil, &out)
+	err := s.client.doJSON(ctx, operation("Team.Retrieve"), http.MethodGet, "/team", nil, nil, &out, opts...)
 	return out, err
 }
 
-func (s *TeamService) Update(ctx context.Context, payload TeamUpdateRequest) (TeamUpdateResponse, error) {
+func (s *TeamService) Update(ctx context.Context, payload TeamUpdateRequest, opts ...CallOption) (TeamUpdateResponse, error) {
 	var out TeamUpdateResponse
-	err := s.client.doJSON(ctx, http.MethodPut, "/team", nil, payload, &out)
+	err := s.client.doJSON(ctx, operation("Team.Update"), http.MethodPut, "/team", nil, payload, &out, opts...)
 	return out, err
 }
 
-func (s *TeamService) Usage(ctx context.Context) (TeamUsageResponse, error) {
+func (s *TeamService) Usage(ctx context.Context, opts ...CallOption) (TeamUsageResponse, error) {
 	var out TeamUsageResponse
-	err := s.client.doJSON(ctx, http.MethodGet, "/team/usage", nil, nil, &out)
+	err := s.client.doJSON(ctx, operation("Team.Usage"), http.MethodGet, "/team/usage", nil, nil, &out, opts...)
 	return out, err
 }
 
-func (s *TeamService) Members(ctx context.Context, query map[string]string) (TeamMembersResponse, error) {
+func (s *TeamService) Members(ctx context.Context, query map[string]string, opts ...CallOption) (TeamMembersResponse, error) {
 	var out TeamMembersResponse
-	err := s.client.doJSON(ctx, http.MethodGet, "/team/members", query, nil, &out)
+	err := s.client.doJSON(ctx, operation("Team.Members"), http.MethodGet, "/team/members", query, nil, &out, opts...)
 	return out, err
 }
 
-func (s *WebhooksService) List(ctx context.Context, query map[string]string) (WebhookIndexResponse, error) {
+func (s *WebhooksService) List(ctx context.Context, query map[string]string, opts ...CallOption) (WebhookIndexResponse, error) {
 	var out WebhookIndexResponse
-	err := s.client.doJSON(ctx, http.MethodGet, "/webhooks", query, nil, &out)
+	err := s.client.doJSON(ctx, operation("Webhooks.List"), http.MethodGet, "/webhooks", query, nil, &out, opts...)
 	return out, err
 }
 
-func (s *WebhooksService) Create(ctx context.Context, payload WebhookStoreRequest) (WebhookStoreResponse, error) {
+func (s *WebhooksService) Create(ctx context.Context, payload WebhookStoreRequest, opts ...CallOption) (WebhookStoreResponse, error) {
 	var out WebhookStoreResponse
-	err := s.client.doJSON(ctx, http.MethodPost, "/webhooks", nil, payload, &out)
+	err := s.client.doJSON(ctx, operation("Webhooks.Create"), http.MethodPost, "/webhooks", nil, payload, &out, opts...)
 	return out, err
 }
 
-func (s *WebhooksService) Retrieve(ctx context.Context, webhookID string) (WebhookShowResponse, error) {
+func (s *WebhooksService) Retrieve(ctx context.Context, webhookID string, opts ...CallOption) (WebhookShowResponse, error) {
 	var out WebhookShowResponse
-	err := s.client.doJSON(ctx, http.MethodGet, "/webhooks/"+segment(webhookID), nil, nil, &out)
+	err := s.client.doJSON(ctx, operation("Webhooks.Retrieve"), http.MethodGet, "/webhooks/"+segment(webhookID), nil, nil, &out, opts...)
 	return out, err
 }
 
-func (s *WebhooksService) Update(ctx context.Context, webhookID string, payload WebhookUpdateRequest) (WebhookUpdateResponse, error) {
+func (s *WebhooksService) Update(ctx context.Context, webhookID string, payload WebhookUpdateRequest, opts ...CallOption) (WebhookUpdateResponse, error) {
 	var out WebhookUpdateResponse
-	err := s.client.doJSON(ctx, http.MethodPut, "/webhooks/"+segment(webhookID), nil, payload, &out)
+	err := s.client.doJSON(ctx, operation("Webhooks.Update"), http.MethodPut, "/webhooks/"+segment(webhookID), nil, payload, &out, opts...)
 	return out, err
 }
 
-func (s *WebhooksService) Delete(ctx context.Context, webhookID string) (WebhookDestroyResponse, error) {
+func (s *WebhooksService) Delete(ctx context.Context, webhookID string, opts ...CallOption) (WebhookDestroyResponse, error) {
 	var out WebhookDestroyResponse
-	err := s.client.doJSON(ctx, http.MethodDelete, "/webhooks/"+segment(webhookID), nil, nil, &out)
+	err := s.client.doJSON(ctx, operation("Webhooks.Delete"), http.MethodDelete, "/webhooks/"+segment(webhookID), nil, nil, &out, opts...)
 	return out, err
 }
 
-func (s *WebhooksService) Test(ctx context.Context, webhookID string) (WebhookTestResponse, error) {
+func (s *WebhooksService) Test(ctx context.Context, webhookID string, opts ...CallOption) (WebhookTestResponse, error) {
 	var out WebhookTestResponse
-	err := s.client.doJSON(ctx, http.MethodPost, "/webhooks/"+segment(webhookID)+"/test", nil, nil, &out)
+	err := s.client.doJSON(ctx, operation("Webhooks.Test"), http.MethodPost, "/webhooks/"+segment(webhookID)+"/test", nil, nil, &out, opts...)
 	return out, err
 }
 
-func (s *WebhooksService) RegenerateSecret(ctx context.Context, webhookID string) (WebhookRegenerateSecretResponse, error) {
+func (s *WebhooksService) RegenerateSecret(ctx context.Context, webhookID string, opts ...CallOption) (WebhookRegenerateSecretResponse, error) {
 	var out WebhookRegenerateSecretResponse
-	err := s.client.doJSON(ctx, http.MethodPost, "/webhooks/"+segment(webhookID)+"/regenerate-secret", nil, nil, &out)
+	err := s.client.doJSON(ctx, operation("Webhooks.RegenerateSecret"), http.MethodPost, "/webhooks/"+segment(webhookID)+"/regenerate-secret", nil, nil, &out, opts...)
 	return out, err
 }
 
-func (s *WebhooksService) Deliveries(ctx context.Context, webhookID string, query map[string]string) (WebhookDeliveriesResponse, error) {
+func (s *WebhooksService) Deliveries(ctx context.Context, webhookID string, query map[string]string, opts ...CallOption) (WebhookDeliveriesResponse, error) {
 	var out WebhookDeliveriesResponse
-	err := s.client.doJSON(ctx, http.MethodGet, "/webhooks/"+segment(webhookID)+"/deliveries", query, nil, &out)
+	err := s.client.doJSON(ctx, operation("Webhooks.Deliveries"), http.MethodGet, "/webhooks/"+segment(webhookID)+"/deliveries", query, nil, &out, opts...)
 	return out, err
 }
 
-func (s *WebhooksService) Delivery(ctx context.Context, webhookID, deliveryID string) (WebhookShowDeliveryResponse, error) {
+func (s *WebhooksService) Delivery(ctx context.Context, webhookID, deliveryID string, opts ...CallOption) (WebhookShowDeliveryResponse, error) {
 	var out WebhookShowDeliveryResponse
-	err := s.client.doJSON(ctx, http.MethodGet, "/webhooks/"+segment(webhookID)+"/deliveries/"+segment(deliveryID), nil, nil, &out)
+	err := s.client.doJSON(ctx, operation("Webhooks.Delivery"), http.MethodGet, "/webhooks/"+segment(webhookID)+"/deliveries/"+segment(deliveryID), nil, nil, &out, opts...)
 	return out, err
 }
 
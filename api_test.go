@@ -3,9 +3,12 @@ package lettermint
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -318,6 +321,75 @@ func TestAPIExposesDocumentedOperations(t *testing.T) {
 	}
 }
 
+func TestAPIDoCallsUnwrappedEndpoints(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer api-token" {
+			t.Fatalf("Authorization = %s, want bearer token", got)
+		}
+		if !strings.HasPrefix(r.Header.Get("User-Agent"), "Lettermint/") {
+			t.Fatalf("User-Agent = %s, want SDK user agent", r.Header.Get("User-Agent"))
+		}
+		switch r.URL.Path {
+		case "/templates":
+			attempts++
+			if attempts == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			var payload map[string]string
+			_ = json.NewDecoder(r.Body).Decode(&payload)
+			if r.Method != http.MethodPost || payload["name"] != "welcome" || r.URL.Query().Get("draft") != "true" {
+				t.Fatalf("request = %s %s %v", r.Method, r.URL, payload)
+			}
+			_, _ = w.Write([]byte(`{"data":{"id":"tpl_1"}}`))
+		case "/templates/tpl_1/preview":
+			_, _ = w.Write([]byte("<p>Welcome</p>"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message":"Not found"}`))
+		}
+	}))
+	defer server.Close()
+
+	api, err := NewAPI("api-token", WithBaseURL(server.URL), WithRetryPolicy(testRetryPolicy()))
+	if err != nil {
+		t.Fatalf("NewAPI() error = %v", err)
+	}
+	ctx := context.Background()
+
+	var out struct {
+		Data struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	err = api.Do(ctx, http.MethodPost, "/templates", map[string]string{"draft": "true"}, map[string]string{"name": "welcome"}, &out,
+		CallIdempotencyKey("create-welcome"))
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	if out.Data.ID != "tpl_1" || attempts != 2 {
+		t.Fatalf("Do() id = %q after %d attempts", out.Data.ID, attempts)
+	}
+
+	resp, err := api.DoRaw(ctx, http.MethodGet, "/templates/tpl_1/preview", nil, nil)
+	if err != nil {
+		t.Fatalf("DoRaw() error = %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || string(body) != "<p>Welcome</p>" {
+		t.Fatalf("DoRaw() = %d %q", resp.StatusCode, body)
+	}
+
+	if err := api.Do(ctx, http.MethodGet, "/missing", nil, nil, nil); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Do() error = %v, want ErrNotFound", err)
+	}
+	if _, err := api.DoRaw(ctx, http.MethodGet, "/missing", nil, nil); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("DoRaw() error = %v, want ErrNotFound", err)
+	}
+}
+
 func floatPtr(value float64) *float64 {
 	return &value
 }
@@ -0,0 +1,369 @@
+package lettermint
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// AttachmentOption configures an attachment added with AttachReader,
+// AttachFile or AttachFS.
+type AttachmentOption func(*Attachment)
+
+// AttachmentContentID sets the Content-ID of the attachment for inline
+// embedding, e.g. "logo" for <img src="cid:logo">.
+func AttachmentContentID(contentID string) AttachmentOption {
+	return func(a *Attachment) {
+		a.ContentID = contentID
+	}
+}
+
+// AttachmentContentType sets the MIME type of the attachment instead of
+// detecting it.
+func AttachmentContentType(contentType string) AttachmentOption {
+	return func(a *Attachment) {
+		a.ContentType = contentType
+	}
+}
+
+// AttachmentError is returned when the content of an attachment cannot be
+// read or the attachment violates the client's AttachmentPolicy.
+type AttachmentError struct {
+	// Filename is the name of the attachment.
+	Filename string
+
+	// ContentType is the MIME type sniffed from the content, if it was
+	// checked.
+	ContentType string
+
+	// Err is the underlying error.
+	Err error
+}
+
+func (e *AttachmentError) Error() string {
+	return fmt.Sprintf("lettermint: attachment %q: %v", e.Filename, e.Err)
+}
+
+func (e *AttachmentError) Unwrap() error {
+	return e.Err
+}
+
+// AttachReader adds an attachment with the content read from r.
+//
+// The content is base64-encoded while the request is sent. If r is an
+// io.Seeker, it is read from its current offset for every attempt; otherwise
+// it is read once and kept in memory for retries. The MIME type is detected
+// from the name and, if that fails, from the content.
+//
+// Example:
+//
+//	email.AttachReader("logo.png", logo, lettermint.AttachmentContentID("logo"))
+func (b *EmailBuilder) AttachReader(name string, r io.Reader, opts ...AttachmentOption) *EmailBuilder {
+	return b.attachSource(name, readerSource(r), opts)
+}
+
+// AttachFile adds the file at the given path as an attachment, named after
+// the file. See AttachReader.
+//
+// The file is opened for every attempt and never held in memory. If it
+// cannot be accessed, Send returns an *AttachmentError.
+func (b *EmailBuilder) AttachFile(filePath string, opts ...AttachmentOption) *EmailBuilder {
+	name := filepath.Base(filePath)
+	if _, err := os.Stat(filePath); err != nil {
+		b.recordError(&AttachmentError{Filename: name, Err: err})
+		return b
+	}
+	return b.attachSource(name, func() (io.ReadCloser, error) {
+		return os.Open(filePath)
+	}, opts)
+}
+
+// AttachFS adds the file at the given path of fsys as an attachment, named
+// after the file. See AttachFile.
+//
+// Example:
+//
+//	//go:embed assets
+//	var assets embed.FS
+//
+//	email.AttachFS(assets, "assets/logo.png", lettermint.AttachmentContentID("logo"))
+func (b *EmailBuilder) AttachFS(fsys fs.FS, filePath string, opts ...AttachmentOption) *EmailBuilder {
+	name := path.Base(filePath)
+	if _, err := fs.Stat(fsys, filePath); err != nil {
+		b.recordError(&AttachmentError{Filename: name, Err: err})
+		return b
+	}
+	return b.attachSource(name, func() (io.ReadCloser, error) {
+		return fsys.Open(filePath)
+	}, opts)
+}
+
+func (b *EmailBuilder) attachSource(name string, open func() (io.ReadCloser, error), opts []AttachmentOption) *EmailBuilder {
+	attachment := Attachment{
+		Filename:    name,
+		ContentType: contentTypeByName(name),
+		source:      &attachmentSource{open: open},
+	}
+	for _, opt := range opts {
+		opt(&attachment)
+	}
+	b.payload.Attachments = append(b.payload.Attachments, attachment)
+	return b
+}
+
+// readerSource returns a function opening r for every attempt. Readers
+// implementing io.ReaderAt, such as files, can be read concurrently by
+// several sends. Other seekers are rewound and locked until the opened
+// reader is closed, so that sends of messages sharing the attachment take
+// turns. All other readers are buffered when first read.
+func readerSource(r io.Reader) func() (io.ReadCloser, error) {
+	if seeker, ok := r.(io.ReadSeeker); ok {
+		if offset, err := seeker.Seek(0, io.SeekCurrent); err == nil {
+			if readerAt, ok := r.(io.ReaderAt); ok {
+				if end, err := seeker.Seek(0, io.SeekEnd); err == nil {
+					if _, err := seeker.Seek(offset, io.SeekStart); err == nil {
+						return func() (io.ReadCloser, error) {
+							return io.NopCloser(io.NewSectionReader(readerAt, offset, end-offset)), nil
+						}
+					}
+				}
+			}
+			var mu sync.Mutex
+			return func() (io.ReadCloser, error) {
+				mu.Lock()
+				if _, err := seeker.Seek(offset, io.SeekStart); err != nil {
+					mu.Unlock()
+					return nil, err
+				}
+				return &lockedReader{Reader: seeker, unlock: mu.Unlock}, nil
+			}
+		}
+	}
+
+	var (
+		once    sync.Once
+		data    []byte
+		readErr error
+	)
+	return func() (io.ReadCloser, error) {
+		once.Do(func() {
+			data, readErr = io.ReadAll(r)
+		})
+		if readErr != nil {
+			return nil, readErr
+		}
+		return io.NopCloser(bytes.NewReader(data)), nil
+	}
+}
+
+// contentTypeByName returns the MIME type for the extension of name, or an
+// empty string if it is unknown.
+func contentTypeByName(name string) string {
+	ext := path.Ext(name)
+	if ext == "" {
+		return ""
+	}
+	return mime.TypeByExtension(strings.ToLower(ext))
+}
+
+// sniffContentType returns the MIME type of content, given its first bytes.
+// It extends http.DetectContentType, which reports executables as
+// application/octet-stream, with the magic numbers of Windows (PE), Linux
+// (ELF) and macOS (Mach-O) executables.
+func sniffContentType(head []byte) string {
+	switch {
+	case bytes.HasPrefix(head, []byte("MZ")):
+		return "application/x-msdownload"
+	case bytes.HasPrefix(head, []byte("\x7fELF")):
+		return "application/x-executable"
+	case bytes.HasPrefix(head, []byte("\xfe\xed\xfa\xce")), bytes.HasPrefix(head, []byte("\xfe\xed\xfa\xcf")),
+		bytes.HasPrefix(head, []byte("\xce\xfa\xed\xfe")), bytes.HasPrefix(head, []byte("\xcf\xfa\xed\xfe")):
+		return "application/x-mach-binary"
+	case bytes.HasPrefix(head, []byte("\xca\xfe\xba\xbe")) && len(head) >= 8 && binary.BigEndian.Uint32(head[4:8]) < 45:
+		// Universal Mach-O binaries share their magic number with Java class
+		// files, which have a major version of 45 or more where the binary
+		// has its small number of architectures.
+		return "application/x-mach-binary"
+	}
+	return http.DetectContentType(head)
+}
+
+// lockedReader is a reader that releases a lock when it is closed.
+type lockedReader struct {
+	io.Reader
+	once   sync.Once
+	unlock func()
+}
+
+func (r *lockedReader) Close() error {
+	r.once.Do(r.unlock)
+	return nil
+}
+
+// attachmentSource opens the unencoded content of an attachment for every
+// attempt.
+type attachmentSource struct {
+	open func() (io.ReadCloser, error)
+}
+
+// streamed reports whether the attachment content is read from a source
+// while the request is sent.
+func (a Attachment) streamed() bool {
+	return a.source != nil
+}
+
+// emailBody is the request body of an email with streamed attachments. The
+// JSON is written while the request is sent, encoding the attachments
+// directly from their sources.
+type emailBody struct {
+	payload *emailPayload
+
+	// pr is the body of the previous attempt, and done is closed when its
+	// writer has returned.
+	pr   *io.PipeReader
+	done chan struct{}
+}
+
+func (b *emailBody) reader() (io.Reader, error) {
+	b.wait()
+	pr, pw := io.Pipe()
+	done := make(chan struct{})
+	b.pr, b.done = pr, done
+	go func() {
+		defer close(done)
+		pw.CloseWithError(b.payload.writeJSON(pw))
+	}()
+	return pr, nil
+}
+
+// wait closes the body of the last attempt and waits for its writer to
+// return, so that the attachment sources are no longer used. The body is
+// closed first because a middleware that returns a response without
+// sending the request never reads it.
+func (b *emailBody) wait() {
+	if b.done != nil {
+		b.pr.Close()
+		<-b.done
+	}
+}
+
+func (b *emailBody) logBytes() []byte {
+	data, _ := json.Marshal(b.payload)
+	return data
+}
+
+// writeJSON writes the payload as JSON to w, streaming the content of its
+// attachments.
+func (p *emailPayload) writeJSON(w io.Writer) error {
+	envelope := *p
+	envelope.Attachments = nil
+	head, err := json.Marshal(&envelope)
+	if err != nil {
+		return fmt.Errorf("failed to marshal email payload: %w", err)
+	}
+	return writeJSONWithAttachments(w, head, p.Attachments)
+}
+
+// writeJSONWithAttachments writes a JSON object to w: head, which must be
+// an object without attachments, followed by the attachments.
+func writeJSONWithAttachments(w io.Writer, head []byte, attachments []Attachment) error {
+	bw := bufio.NewWriterSize(w, 32<<10)
+	bw.Write(head[:len(head)-1])
+	bw.WriteString(`,"attachments":[`)
+	for i, attachment := range attachments {
+		if i > 0 {
+			bw.WriteByte(',')
+		}
+		if err := attachment.writeJSON(bw); err != nil {
+			return err
+		}
+	}
+	bw.WriteString(`]}`)
+	return bw.Flush()
+}
+
+// writeJSON writes the attachment as JSON to w. The content of a streamed
+// attachment is base64-encoded while it is read, and its MIME type is
+// sniffed from the content if it could not be detected from the name.
+func (a Attachment) writeJSON(w *bufio.Writer) error {
+	if !a.streamed() {
+		data, err := json.Marshal(a)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(data)
+		return err
+	}
+
+	r, err := a.source.open()
+	if err != nil {
+		return &AttachmentError{Filename: a.Filename, Err: err}
+	}
+	defer r.Close()
+
+	w.WriteByte('{')
+	writeJSONField(w, "filename", a.Filename)
+	w.WriteString(`,"content":"`)
+	source := &attachmentReader{r: r, sniff: a.ContentType == ""}
+	encoder := base64.NewEncoder(base64.StdEncoding, w)
+	if _, err := io.Copy(encoder, source); err != nil {
+		if source.err != nil {
+			return &AttachmentError{Filename: a.Filename, Err: source.err}
+		}
+		return err
+	}
+	encoder.Close()
+	w.WriteByte('"')
+
+	contentType := a.ContentType
+	if contentType == "" {
+		contentType = sniffContentType(source.head)
+	}
+	w.WriteByte(',')
+	writeJSONField(w, "content_type", contentType)
+	if a.ContentID != "" {
+		w.WriteByte(',')
+		writeJSONField(w, "content_id", a.ContentID)
+	}
+	_, err = w.WriteString("}")
+	return err
+}
+
+// writeJSONField writes a JSON object member with a string value.
+func writeJSONField(w *bufio.Writer, key, value string) {
+	data, _ := json.Marshal(value)
+	w.WriteString(`"` + key + `":`)
+	w.Write(data)
+}
+
+// attachmentReader reads the content of an attachment, remembering read
+// errors and the first bytes for content sniffing.
+type attachmentReader struct {
+	r     io.Reader
+	sniff bool
+	head  []byte
+	err   error
+}
+
+func (r *attachmentReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if r.sniff && len(r.head) < 512 {
+		r.head = append(r.head, p[:min(n, 512-len(r.head))]...)
+	}
+	if err != nil && err != io.EOF {
+		r.err = err
+	}
+	return n, err
+}
@@ -0,0 +1,198 @@
+package lettermint
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+func TestEmailBuilder_AttachSources(t *testing.T) {
+	report := bytes.Repeat([]byte("quarterly numbers\n"), 10000)
+	dir := t.TempDir()
+	reportPath := filepath.Join(dir, "report.pdf")
+	if err := os.WriteFile(reportPath, report, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	assets := fstest.MapFS{"assets/logo.png": {Data: pngHeader}}
+
+	var attempts int
+	var got emailPayload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		got = emailPayload{}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decode body: %v", err)
+		}
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"message_id":"msg_1","status":"pending"}`))
+	}))
+	defer server.Close()
+
+	client, _ := New("test-token", WithBaseURL(server.URL), WithRetryPolicy(testRetryPolicy()))
+	_, err := client.Email(context.Background()).
+		From("sender@example.com").
+		To("recipient@example.com").
+		Subject("Report").
+		HTML(`<img src="cid:logo">`).
+		Attach("notes.txt", "bm90ZXM=").
+		AttachReader("data", io.MultiReader(bytes.NewReader(pngHeader))).
+		AttachFile(reportPath).
+		AttachFS(assets, "assets/logo.png", AttachmentContentID("logo")).
+		IdempotencyKey("report-1").
+		Send()
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if attempts != 2 {
+		t.Fatalf("attempts = %d, want 2", attempts)
+	}
+
+	want := []Attachment{
+		{Filename: "notes.txt", Content: "bm90ZXM="},
+		{Filename: "data", Content: base64.StdEncoding.EncodeToString(pngHeader), ContentType: "image/png"},
+		{Filename: "report.pdf", Content: base64.StdEncoding.EncodeToString(report), ContentType: "application/pdf"},
+		{Filename: "logo.png", Content: base64.StdEncoding.EncodeToString(pngHeader), ContentType: "image/png", ContentID: "logo"},
+	}
+	if len(got.Attachments) != len(want) {
+		t.Fatalf("attachments = %d, want %d", len(got.Attachments), len(want))
+	}
+	for i, attachment := range got.Attachments {
+		if attachment != want[i] {
+			t.Errorf("attachment %d = %+v, want %+v", i, attachment, want[i])
+		}
+	}
+	if got.From != "sender@example.com" || got.Subject != "Report" {
+		t.Errorf("payload = %+v", got)
+	}
+}
+
+func TestEmailBuilder_AttachReaderSeeker(t *testing.T) {
+	var contents []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload emailPayload
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		contents = append(contents, payload.Attachments[0].Content)
+		if len(contents) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		_, _ = w.Write([]byte(`{"message_id":"msg_1","status":"pending"}`))
+	}))
+	defer server.Close()
+
+	reader := strings.NewReader("skip:content")
+	_, _ = reader.Seek(5, io.SeekStart)
+
+	client, _ := New("test-token", WithBaseURL(server.URL), WithRetryPolicy(testRetryPolicy()))
+	_, err := client.Email(context.Background()).
+		From("sender@example.com").
+		To("recipient@example.com").
+		Subject("Seeker").
+		Text("Body").
+		AttachReader("note.txt", reader).
+		IdempotencyKey("seeker-1").
+		Send()
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	want := base64.StdEncoding.EncodeToString([]byte("content"))
+	if len(contents) != 2 || contents[0] != want || contents[1] != want {
+		t.Fatalf("contents = %v, want %q for both attempts", contents, want)
+	}
+}
+
+func TestEmailBuilder_AttachReader_BodyNotRead(t *testing.T) {
+	// A middleware that answers without sending the request never reads the
+	// streamed body, which must not block the send.
+	stub := func(http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{"Content-Type": {"application/json"}},
+				Body:       io.NopCloser(strings.NewReader(`{"message_id":"msg_1","status":"pending"}`)),
+				Request:    req,
+			}, nil
+		})
+	}
+	client, _ := New("test-token", WithMiddleware(stub))
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := client.Email(context.Background()).
+			From("sender@example.com").
+			To("recipient@example.com").
+			Subject("Stubbed").
+			Text("Body").
+			AttachReader("large.bin", bytes.NewReader(make([]byte, 1<<20))).
+			Send()
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Send() did not return")
+	}
+}
+
+type failingReader struct{ err error }
+
+func (r failingReader) Read([]byte) (int, error) { return 0, r.err }
+
+func TestEmailBuilder_AttachErrors(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		_, _ = io.Copy(io.Discard, r.Body)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client, _ := New("test-token", WithBaseURL(server.URL), WithRetryPolicy(testRetryPolicy()))
+	email := func() *EmailBuilder {
+		return client.Email(context.Background()).
+			From("sender@example.com").
+			To("recipient@example.com").
+			Subject("Broken").
+			Text("Body").
+			IdempotencyKey("broken-1")
+	}
+
+	_, err := email().AttachFile(filepath.Join(t.TempDir(), "missing.pdf")).Send()
+	var attachmentErr *AttachmentError
+	if !errors.Is(err, ErrInvalidRequest) || !errors.Is(err, fs.ErrNotExist) || !errors.As(err, &attachmentErr) || attachmentErr.Filename != "missing.pdf" {
+		t.Fatalf("AttachFile() Send error = %v, want missing.pdf attachment error", err)
+	}
+	if requests != 0 {
+		t.Fatalf("requests = %d, want none for a missing file", requests)
+	}
+
+	errDisk := errors.New("disk failure")
+	_, err = email().AttachReader("broken.bin", failingReader{errDisk}).Send()
+	if !errors.Is(err, ErrInvalidRequest) || !errors.Is(err, errDisk) || !errors.As(err, &attachmentErr) || attachmentErr.Filename != "broken.bin" {
+		t.Fatalf("AttachReader() Send error = %v, want broken.bin attachment error", err)
+	}
+	if IsRetryable(err) {
+		t.Fatalf("IsRetryable(%v) = true, want attachment errors to be permanent", err)
+	}
+}
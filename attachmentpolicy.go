@@ -0,0 +1,268 @@
+package lettermint
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+// BlockedFileTypesProvider provides the file types Lettermint refuses as
+// attachments. *APIClient implements it.
+type BlockedFileTypesProvider interface {
+	BlockedFileTypes(ctx context.Context, opts ...CallOption) (BlockedFileTypesResponse, error)
+}
+
+// AttachmentPolicy configures the checks of attachments made before an email
+// is sent. See WithAttachmentPolicy.
+type AttachmentPolicy struct {
+	// BlockedFileTypes provides the blocked extensions and MIME types. If nil,
+	// file types are not checked.
+	BlockedFileTypes BlockedFileTypesProvider
+
+	// CacheTTL is how long the blocked file types are cached. Defaults to one
+	// hour. Once the cache has expired, the cached types keep being used
+	// while they are fetched again in the background.
+	CacheTTL time.Duration
+
+	// MaxAttachmentBytes limits the size of each attachment before encoding.
+	// Zero means no limit.
+	MaxAttachmentBytes int64
+
+	// MaxPayloadBytes limits the estimated size of the request body,
+	// including the base64-encoded attachments. Zero means no limit.
+	MaxPayloadBytes int64
+}
+
+// DefaultAttachmentCacheTTL is the default of AttachmentPolicy.CacheTTL.
+const DefaultAttachmentCacheTTL = time.Hour
+
+// attachmentPolicy checks the attachments of an email against an
+// AttachmentPolicy, caching the blocked file types.
+type attachmentPolicy struct {
+	config AttachmentPolicy
+	now    func() time.Time
+
+	mu        sync.Mutex
+	blocked   *BlockedFileTypesResponse
+	fetchedAt time.Time
+	fetch     *blockedFileTypesFetch // in flight, if any
+}
+
+// blockedFileTypesFetch is a fetch of the blocked file types shared by all
+// sends that need them.
+type blockedFileTypesFetch struct {
+	done    chan struct{} // closed when the fetch has finished
+	blocked *BlockedFileTypesResponse
+	err     error
+}
+
+func newAttachmentPolicy(config AttachmentPolicy) *attachmentPolicy {
+	if config.CacheTTL <= 0 {
+		config.CacheTTL = DefaultAttachmentCacheTTL
+	}
+	return &attachmentPolicy{config: config, now: time.Now}
+}
+
+// blockedFileTypes returns the cached blocked file types. When the cache has
+// expired, the stale types are returned and fetched again in the background;
+// without a cache, the send waits for the fetch. Only one fetch runs at a
+// time, and it is not canceled with the context of the send that started it.
+func (p *attachmentPolicy) blockedFileTypes(ctx context.Context) (*BlockedFileTypesResponse, error) {
+	p.mu.Lock()
+	blocked := p.blocked
+	if blocked != nil && p.now().Sub(p.fetchedAt) < p.config.CacheTTL {
+		p.mu.Unlock()
+		return blocked, nil
+	}
+	fetch := p.fetch
+	if fetch == nil {
+		fetch = &blockedFileTypesFetch{done: make(chan struct{})}
+		p.fetch = fetch
+		go p.fetchBlockedFileTypes(context.WithoutCancel(ctx), fetch)
+	}
+	p.mu.Unlock()
+
+	if blocked != nil {
+		return blocked, nil
+	}
+	select {
+	case <-fetch.done:
+	case <-ctx.Done():
+		return nil, fmt.Errorf("failed to load blocked file types: %w", ctx.Err())
+	}
+	if fetch.err != nil {
+		return nil, fmt.Errorf("failed to load blocked file types: %w", fetch.err)
+	}
+	return fetch.blocked, nil
+}
+
+// fetchBlockedFileTypes runs a fetch and caches its result. If it fails, a
+// stale cache is kept.
+func (p *attachmentPolicy) fetchBlockedFileTypes(ctx context.Context, fetch *blockedFileTypesFetch) {
+	blocked, err := p.config.BlockedFileTypes.BlockedFileTypes(ctx)
+
+	p.mu.Lock()
+	if err == nil {
+		p.blocked = &blocked
+		p.fetchedAt = p.now()
+	}
+	fetch.blocked, fetch.err = p.blocked, err
+	p.fetch = nil
+	p.mu.Unlock()
+	close(fetch.done)
+}
+
+// check returns an *AttachmentError for the first attachment of the payload
+// that violates the policy.
+func (p *attachmentPolicy) check(ctx context.Context, payload *emailPayload) error {
+	if len(payload.Attachments) == 0 {
+		return nil
+	}
+
+	var blocked *BlockedFileTypesResponse
+	if p.config.BlockedFileTypes != nil {
+		var err error
+		if blocked, err = p.blockedFileTypes(ctx); err != nil {
+			return err
+		}
+	}
+
+	envelope := *payload
+	envelope.Attachments = nil
+	head, err := json.Marshal(&envelope)
+	if err != nil {
+		return fmt.Errorf("failed to marshal email payload: %w", err)
+	}
+	total := int64(len(head) + len(`,"attachments":[]`))
+
+	for _, attachment := range payload.Attachments {
+		info, err := attachment.inspect()
+		if err != nil {
+			return &AttachmentError{Filename: attachment.Filename, Err: err}
+		}
+		attachmentErr := func(err error) error {
+			return &AttachmentError{Filename: attachment.Filename, ContentType: info.contentType, Err: err}
+		}
+
+		if blocked != nil {
+			contentTypes := []string{attachment.ContentType}
+			if info.contentType != "application/octet-stream" {
+				// Unrecognized content says nothing about its type.
+				contentTypes = append(contentTypes, info.contentType)
+			}
+			if reason := blockedReason(blocked, attachment.Filename, contentTypes...); reason != "" {
+				return attachmentErr(fmt.Errorf("%w: %s", ErrBlockedFileType, reason))
+			}
+		}
+		if limit := p.config.MaxAttachmentBytes; limit > 0 && info.size > limit {
+			return attachmentErr(fmt.Errorf("%w: attachment is %d bytes, limit is %d bytes", ErrPayloadTooLarge, info.size, limit))
+		}
+		total += info.encodedSize + 1
+		if limit := p.config.MaxPayloadBytes; limit > 0 && total > limit {
+			return attachmentErr(fmt.Errorf("%w: payload exceeds %d bytes", ErrPayloadTooLarge, limit))
+		}
+	}
+	return nil
+}
+
+// attachmentInfo describes the content of an attachment.
+type attachmentInfo struct {
+	size        int64  // unencoded size
+	encodedSize int64  // size of the attachment's JSON object
+	contentType string // sniffed from the content with sniffContentType
+}
+
+// inspect reads the attachment to determine its size and content type.
+// Streamed attachments are opened once for this and again when sent.
+//
+// The content type is sniffed from the first 512 bytes, which recognizes
+// the formats of http.DetectContentType (images, audio, video, PDF, ZIP,
+// gzip, RAR, fonts, HTML, XML and text) and executables. Other content,
+// including documents in ZIP containers such as DOCX, is not distinguished
+// further.
+func (a Attachment) inspect() (attachmentInfo, error) {
+	var info attachmentInfo
+	meta := a
+	meta.Content = ""
+	data, err := json.Marshal(meta)
+	if err != nil {
+		return info, err
+	}
+
+	if !a.streamed() {
+		content, err := base64.StdEncoding.DecodeString(a.Content)
+		if err != nil {
+			return info, fmt.Errorf("invalid base64 content: %w", err)
+		}
+		info.size = int64(len(content))
+		info.encodedSize = int64(len(data) + len(a.Content))
+		info.contentType = sniffContentType(content)
+		return info, nil
+	}
+
+	r, err := a.source.open()
+	if err != nil {
+		return info, err
+	}
+	defer r.Close()
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(r, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return info, err
+	}
+	info.contentType = sniffContentType(head[:n])
+
+	if file, ok := r.(interface{ Stat() (fs.FileInfo, error) }); ok {
+		stat, err := file.Stat()
+		if err != nil {
+			return info, err
+		}
+		info.size = stat.Size()
+	} else {
+		rest, err := io.Copy(io.Discard, r)
+		if err != nil {
+			return info, err
+		}
+		info.size = int64(n) + rest
+	}
+	info.encodedSize = int64(len(data)) + int64(base64.StdEncoding.EncodedLen(int(info.size)))
+	if a.ContentType == "" {
+		// The sniffed type is sent with the content.
+		info.encodedSize += int64(len(`,"content_type":""`) + len(info.contentType))
+	}
+	return info, nil
+}
+
+// blockedReason returns why an attachment is blocked, or an empty string if
+// it is not.
+func blockedReason(blocked *BlockedFileTypesResponse, filename string, contentTypes ...string) string {
+	if ext := strings.TrimPrefix(strings.ToLower(path.Ext(filename)), "."); ext != "" {
+		for _, blockedExt := range blocked.Extensions {
+			if ext == strings.TrimPrefix(strings.ToLower(blockedExt), ".") {
+				return fmt.Sprintf("extension %q is blocked", ext)
+			}
+		}
+	}
+	for _, contentType := range contentTypes {
+		mediaType, _, err := mime.ParseMediaType(contentType)
+		if err != nil {
+			continue
+		}
+		for _, blockedType := range blocked.MimeTypes {
+			blockedType = strings.ToLower(blockedType)
+			if mediaType == blockedType || strings.HasSuffix(blockedType, "/*") && strings.HasPrefix(mediaType, strings.TrimSuffix(blockedType, "*")) {
+				return fmt.Sprintf("MIME type %q is blocked", mediaType)
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,277 @@
+package lettermint
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type blockedFileTypesFunc func(ctx context.Context) (BlockedFileTypesResponse, error)
+
+func (f blockedFileTypesFunc) BlockedFileTypes(ctx context.Context, opts ...CallOption) (BlockedFileTypesResponse, error) {
+	return f(ctx)
+}
+
+func TestAttachmentPolicy_BlockedFileTypes(t *testing.T) {
+	var fetches, sends int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/blocked-file-types":
+			fetches++
+			_, _ = w.Write([]byte(`{"extensions":[".EXE","bat"],"mime_types":["application/x-msdownload","image/*"]}`))
+		case "/send":
+			sends++
+			_, _ = w.Write([]byte(`{"message_id":"msg_1","status":"pending"}`))
+		}
+	}))
+	defer server.Close()
+
+	api, _ := NewAPI("team-token", WithBaseURL(server.URL))
+	client, _ := New("test-token", WithBaseURL(server.URL), WithAttachmentPolicy(AttachmentPolicy{BlockedFileTypes: api}))
+	email := func() *EmailBuilder {
+		return client.Email(context.Background()).
+			From("sender@example.com").
+			To("recipient@example.com").
+			Subject("Files").
+			Text("Body")
+	}
+
+	_, err := email().Attach("notes.txt", "bm90ZXM=").Attach("setup.exe", "TVqQAAMAAAAEAAAA").Send()
+	var attachmentErr *AttachmentError
+	if !errors.Is(err, ErrInvalidRequest) || !errors.Is(err, ErrBlockedFileType) || !errors.As(err, &attachmentErr) || attachmentErr.Filename != "setup.exe" {
+		t.Fatalf("Send() error = %v, want blocked setup.exe", err)
+	}
+
+	// Sniffed from the content, not the name.
+	_, err = email().AttachReader("photo.dat", strings.NewReader(string(pngHeader))).Send()
+	if !errors.Is(err, ErrBlockedFileType) || !errors.As(err, &attachmentErr) || attachmentErr.Filename != "photo.dat" || attachmentErr.ContentType != "image/png" {
+		t.Fatalf("Send() error = %v, want blocked photo.dat", err)
+	}
+
+	if _, err := email().AttachReader("notes.txt", strings.NewReader("plain notes")).Send(); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if fetches != 1 || sends != 1 {
+		t.Fatalf("fetches = %d, sends = %d, want 1 and 1", fetches, sends)
+	}
+}
+
+// waitForFetch waits for the fetch of blocked file types in flight, if any.
+func (p *attachmentPolicy) waitForFetch() {
+	p.mu.Lock()
+	fetch := p.fetch
+	p.mu.Unlock()
+	if fetch != nil {
+		<-fetch.done
+	}
+}
+
+func TestAttachmentPolicy_Cache(t *testing.T) {
+	errUnavailable := errors.New("unavailable")
+	var fetches int
+	var fail bool
+	policy := newAttachmentPolicy(AttachmentPolicy{
+		CacheTTL: time.Minute,
+		BlockedFileTypes: blockedFileTypesFunc(func(ctx context.Context) (BlockedFileTypesResponse, error) {
+			fetches++
+			if fail {
+				return BlockedFileTypesResponse{}, errUnavailable
+			}
+			return BlockedFileTypesResponse{Extensions: []string{"exe"}}, nil
+		}),
+	})
+	now := time.Unix(1700000000, 0)
+	policy.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if _, err := policy.blockedFileTypes(ctx); err != nil {
+			t.Fatalf("blockedFileTypes() error = %v", err)
+		}
+	}
+	if fetches != 1 {
+		t.Fatalf("fetches = %d, want 1 within the TTL", fetches)
+	}
+
+	// An expired cache is used while it is fetched again, and kept when
+	// fetching fails.
+	now = now.Add(2 * time.Minute)
+	fail = true
+	blocked, err := policy.blockedFileTypes(ctx)
+	policy.waitForFetch()
+	if err != nil || fetches != 2 || len(blocked.Extensions) != 1 {
+		t.Fatalf("blockedFileTypes() = %v, %v after %d fetches, want stale cache", blocked, err, fetches)
+	}
+	fail = false
+	_, _ = policy.blockedFileTypes(ctx)
+	policy.waitForFetch()
+	if _, err := policy.blockedFileTypes(ctx); err != nil || fetches != 3 {
+		t.Fatalf("blockedFileTypes() error = %v after %d fetches, want a refreshed cache", err, fetches)
+	}
+
+	fail = true
+	empty := newAttachmentPolicy(policy.config)
+	if _, err := empty.blockedFileTypes(ctx); !errors.Is(err, errUnavailable) {
+		t.Fatalf("blockedFileTypes() error = %v, want errUnavailable without cache", err)
+	}
+}
+
+func TestAttachmentPolicy_SharedFetch(t *testing.T) {
+	release := make(chan struct{})
+	var fetches atomic.Int32
+	policy := newAttachmentPolicy(AttachmentPolicy{
+		BlockedFileTypes: blockedFileTypesFunc(func(ctx context.Context) (BlockedFileTypesResponse, error) {
+			fetches.Add(1)
+			<-release
+			return BlockedFileTypesResponse{Extensions: []string{"exe"}}, ctx.Err()
+		}),
+	})
+
+	// The send that starts the fetch gives up, but the fetch continues for
+	// the others.
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := policy.blockedFileTypes(canceled); !errors.Is(err, context.Canceled) {
+		t.Fatalf("blockedFileTypes() error = %v, want context.Canceled", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if blocked, err := policy.blockedFileTypes(context.Background()); err != nil || len(blocked.Extensions) != 1 {
+				t.Errorf("blockedFileTypes() = %v, %v", blocked, err)
+			}
+		}()
+	}
+	close(release)
+	wg.Wait()
+	if n := fetches.Load(); n != 1 {
+		t.Fatalf("fetches = %d, want 1 shared by all sends", n)
+	}
+}
+
+func TestAttachmentPolicy_SniffsExecutables(t *testing.T) {
+	policy := newAttachmentPolicy(AttachmentPolicy{
+		BlockedFileTypes: blockedFileTypesFunc(func(ctx context.Context) (BlockedFileTypesResponse, error) {
+			return BlockedFileTypesResponse{MimeTypes: []string{"application/x-msdownload", "application/x-executable", "application/octet-stream"}}, nil
+		}),
+	})
+	tests := []struct {
+		filename string
+		content  string
+		blocked  string
+	}{
+		{"report.pdf", "MZ\x90\x00\x03\x00\x00\x00", "application/x-msdownload"},
+		{"tool.dat", "\x7fELF\x02\x01\x01", "application/x-executable"},
+		{"data.bin", "\x00\x01\x02\x03 unrecognized", ""},
+		{"notes.txt", "plain notes", ""},
+	}
+	for _, tt := range tests {
+		payload := &emailPayload{Attachments: []Attachment{
+			{Filename: tt.filename, Content: base64.StdEncoding.EncodeToString([]byte(tt.content))},
+		}}
+		err := policy.check(context.Background(), payload)
+		var attachmentErr *AttachmentError
+		if tt.blocked == "" {
+			if err != nil {
+				t.Errorf("check(%s) error = %v, want nil", tt.filename, err)
+			}
+		} else if !errors.Is(err, ErrBlockedFileType) || !errors.As(err, &attachmentErr) || attachmentErr.ContentType != tt.blocked {
+			t.Errorf("check(%s) error = %v, want %s blocked", tt.filename, err, tt.blocked)
+		}
+	}
+}
+
+func TestSniffContentType(t *testing.T) {
+	tests := []struct {
+		head string
+		want string
+	}{
+		{"MZ\x90\x00", "application/x-msdownload"},
+		{"\x7fELF\x02", "application/x-executable"},
+		{"\xcf\xfa\xed\xfe\x07\x00\x00\x01", "application/x-mach-binary"},
+		{"\xca\xfe\xba\xbe\x00\x00\x00\x02", "application/x-mach-binary"},
+		{"\xca\xfe\xba\xbe\x00\x00\x00\x41", "application/octet-stream"}, // Java class file
+		{"%PDF-1.7", "application/pdf"},
+	}
+	for _, tt := range tests {
+		if got := sniffContentType([]byte(tt.head)); got != tt.want {
+			t.Errorf("sniffContentType(%q) = %q, want %q", tt.head, got, tt.want)
+		}
+	}
+}
+
+func TestAttachmentPolicy_SizeLimits(t *testing.T) {
+	sends := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sends++
+		_, _ = w.Write([]byte(`{"message_id":"msg_1","status":"pending"}`))
+	}))
+	defer server.Close()
+
+	client, _ := New("test-token", WithBaseURL(server.URL), WithAttachmentPolicy(AttachmentPolicy{
+		MaxAttachmentBytes: 1000,
+		MaxPayloadBytes:    1500,
+	}))
+	email := func() *EmailBuilder {
+		return client.Email(context.Background()).
+			From("sender@example.com").
+			To("recipient@example.com").
+			Subject("Sizes").
+			Text("Body")
+	}
+
+	small := strings.Repeat("a", 600)
+	if _, err := email().AttachReader("a.txt", strings.NewReader(small)).Send(); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	_, err := email().Attach("big.txt", base64.StdEncoding.EncodeToString([]byte(strings.Repeat("b", 1001)))).Send()
+	var attachmentErr *AttachmentError
+	if !errors.Is(err, ErrPayloadTooLarge) || !errors.As(err, &attachmentErr) || attachmentErr.Filename != "big.txt" {
+		t.Fatalf("Send() error = %v, want big.txt too large", err)
+	}
+
+	// Each attachment is within the limit, but both together are not.
+	_, err = email().
+		AttachReader("a.txt", strings.NewReader(small)).
+		AttachReader("b.txt", strings.NewReader(small)).
+		Send()
+	if !errors.Is(err, ErrPayloadTooLarge) || !errors.As(err, &attachmentErr) || attachmentErr.Filename != "b.txt" {
+		t.Fatalf("Send() error = %v, want payload too large at b.txt", err)
+	}
+	if sends != 1 {
+		t.Fatalf("sends = %d, want 1", sends)
+	}
+}
+
+func TestAttachmentPolicy_InvalidBase64(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request sent with an attachment that is not valid base64")
+	}))
+	defer server.Close()
+
+	client, _ := New("test-token", WithBaseURL(server.URL), WithAttachmentPolicy(AttachmentPolicy{MaxAttachmentBytes: 1000}))
+	_, err := client.Email(context.Background()).
+		From("sender@example.com").
+		To("recipient@example.com").
+		Subject("Invalid").
+		Text("Body").
+		Attach("report.pdf", "not base64!").
+		Send()
+
+	var attachmentErr *AttachmentError
+	var decodeErr base64.CorruptInputError
+	if !errors.As(err, &attachmentErr) || attachmentErr.Filename != "report.pdf" || !errors.As(err, &decodeErr) {
+		t.Fatalf("Send() error = %v, want an *AttachmentError wrapping the decode error", err)
+	}
+}
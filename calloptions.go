@@ -0,0 +1,101 @@
+package lettermint
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// CallOption configures a single API call, overriding the client settings
+// for that call only.
+//
+// Example:
+//
+//	domains, err := api.Domains.List(ctx, nil,
+//	    lettermint.CallTimeout(5*time.Second),
+//	    lettermint.CallHeader("X-Correlation-Id", correlationID),
+//	)
+type CallOption func(*callOptions)
+
+type callOptions struct {
+	timeout          time.Duration
+	header           http.Header
+	retryPolicy      *RetryPolicy
+	maxResponseBytes *int64
+}
+
+// CallTimeout limits the duration of the call, including retries.
+func CallTimeout(timeout time.Duration) CallOption {
+	return func(o *callOptions) {
+		o.timeout = timeout
+	}
+}
+
+// CallHeader adds a header to the request. The option can be used multiple
+// times; values for the same key are added.
+func CallHeader(key, value string) CallOption {
+	return func(o *callOptions) {
+		o.header.Add(key, value)
+	}
+}
+
+// CallIdempotencyKey sets the Idempotency-Key header of the request.
+//
+// Like EmailBuilder.IdempotencyKey, this allows POST requests to be retried
+// by the retry policy.
+func CallIdempotencyKey(key string) CallOption {
+	return func(o *callOptions) {
+		o.header.Set("Idempotency-Key", key)
+	}
+}
+
+// CallRetryPolicy uses the given retry policy instead of the client's retry
+// policy. Use RetryPolicy{} to disable retries for the call.
+func CallRetryPolicy(policy RetryPolicy) CallOption {
+	return func(o *callOptions) {
+		o.retryPolicy = &policy
+	}
+}
+
+// CallMaxResponseBytes overrides the client's response size limit, like
+// LimitResponseBytes.
+func CallMaxResponseBytes(maxBytes int64) CallOption {
+	return func(o *callOptions) {
+		o.maxResponseBytes = &maxBytes
+	}
+}
+
+type retryPolicyKey struct{}
+
+// applyCallOptions returns the context and extra request headers of a call.
+// The returned cancel function must be called when the call is done.
+func applyCallOptions(ctx context.Context, opts []CallOption) (context.Context, http.Header, context.CancelFunc) {
+	if len(opts) == 0 {
+		return ctx, nil, func() {}
+	}
+
+	o := callOptions{header: http.Header{}}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if o.retryPolicy != nil {
+		ctx = context.WithValue(ctx, retryPolicyKey{}, *o.retryPolicy)
+	}
+	if o.maxResponseBytes != nil {
+		ctx = LimitResponseBytes(ctx, *o.maxResponseBytes)
+	}
+	cancel := func() {}
+	if o.timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, o.timeout)
+	}
+	return ctx, o.header, cancel
+}
+
+// retryPolicyFor returns the retry policy for a call.
+func (c *Client) retryPolicyFor(ctx context.Context) RetryPolicy {
+	if policy, ok := ctx.Value(retryPolicyKey{}).(RetryPolicy); ok {
+		return policy
+	}
+	return c.retryPolicy
+}
@@ -0,0 +1,103 @@
+package lettermint
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCallOptions_HeadersAndIdempotencyKey(t *testing.T) {
+	var got http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+		_, _ = w.Write([]byte(`{"data":{"id":"proj_1"}}`))
+	}))
+	defer server.Close()
+
+	api, _ := NewAPI("api-token", WithBaseURL(server.URL))
+	_, err := api.Projects.Create(context.Background(), ProjectStoreRequest{Name: "Billing"},
+		CallHeader("X-Correlation-Id", "corr_1"),
+		CallHeader("X-Correlation-Id", "corr_2"),
+		CallIdempotencyKey("create-billing"),
+	)
+	if err != nil {
+		t.Fatalf("Projects.Create() error = %v", err)
+	}
+
+	if values := got.Values("X-Correlation-Id"); len(values) != 2 || values[0] != "corr_1" || values[1] != "corr_2" {
+		t.Errorf("X-Correlation-Id = %v, want [corr_1 corr_2]", values)
+	}
+	if got.Get("Idempotency-Key") != "create-billing" {
+		t.Errorf("Idempotency-Key = %q, want create-billing", got.Get("Idempotency-Key"))
+	}
+	if got.Get("Authorization") != "Bearer api-token" {
+		t.Errorf("Authorization = %q, want client auth to be kept", got.Get("Authorization"))
+	}
+}
+
+func TestCallOptions_Timeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer server.Close()
+
+	api, _ := NewAPI("api-token", WithBaseURL(server.URL))
+	start := time.Now()
+	_, err := api.Team.Retrieve(context.Background(), CallTimeout(20*time.Millisecond))
+	if !errors.Is(err, ErrTimeout) {
+		t.Fatalf("Team.Retrieve() error = %v, want ErrTimeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Fatalf("Team.Retrieve() took %v, want call timeout to apply", elapsed)
+	}
+}
+
+func TestCallOptions_RetryPolicy(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":[]}`))
+	}))
+	defer server.Close()
+
+	// The client does not retry, the call does.
+	api, _ := NewAPI("api-token", WithBaseURL(server.URL))
+	if _, err := api.Webhooks.List(context.Background(), nil, CallRetryPolicy(testRetryPolicy())); err != nil {
+		t.Fatalf("Webhooks.List() error = %v", err)
+	}
+	if attempts != 3 {
+		t.Fatalf("attempts = %d, want 3", attempts)
+	}
+
+	// The client retries, the call does not.
+	attempts = 0
+	api, _ = NewAPI("api-token", WithBaseURL(server.URL), WithRetryPolicy(testRetryPolicy()))
+	if _, err := api.Webhooks.List(context.Background(), nil, CallRetryPolicy(RetryPolicy{})); !errors.Is(err, ErrServerError) {
+		t.Fatalf("Webhooks.List() error = %v, want ErrServerError", err)
+	}
+	if attempts != 1 {
+		t.Fatalf("attempts = %d, want 1", attempts)
+	}
+}
+
+func TestCallOptions_MaxResponseBytes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"data":[{"id":"sup_1"},{"id":"sup_2"}]}`))
+	}))
+	defer server.Close()
+
+	api, _ := NewAPI("api-token", WithBaseURL(server.URL))
+	if _, err := api.Suppressions.List(context.Background(), nil, CallMaxResponseBytes(10)); !errors.Is(err, ErrResponseTooLarge) {
+		t.Fatalf("Suppressions.List() error = %v, want ErrResponseTooLarge", err)
+	}
+}
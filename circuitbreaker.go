@@ -0,0 +1,235 @@
+package lettermint
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// CircuitState is the state of a circuit breaker.
+type CircuitState int
+
+const (
+	// CircuitClosed lets all requests through. This is the normal state.
+	CircuitClosed CircuitState = iota
+
+	// CircuitOpen rejects all requests with ErrCircuitOpen until the
+	// cool-down has elapsed.
+	CircuitOpen
+
+	// CircuitHalfOpen lets a single trial request through at a time to
+	// probe whether the API has recovered.
+	CircuitHalfOpen
+)
+
+// String returns the name of the state.
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// CircuitBreakerConfig configures the circuit breaker enabled with
+// WithCircuitBreaker.
+type CircuitBreakerConfig struct {
+	// FailureThreshold is the number of consecutive failed requests that
+	// opens the circuit.
+	FailureThreshold int
+
+	// CoolDown is how long the circuit stays open before a trial request
+	// is let through.
+	CoolDown time.Duration
+
+	// SuccessThreshold is the number of consecutive successful trial
+	// requests that close a half-open circuit.
+	SuccessThreshold int
+
+	// IsFailure reports whether an error counts as a failure. Errors that
+	// are not failures, such as validation errors, count as successes
+	// because the API responded. Canceled requests are never counted.
+	//
+	// If nil, server errors, timeouts and network errors are failures.
+	IsFailure func(err error) bool
+
+	// OnStateChange is called whenever the circuit changes state, e.g. to
+	// alert when it opens. It is called synchronously and must not block.
+	OnStateChange func(from, to CircuitState)
+}
+
+// DefaultCircuitBreakerConfig returns a circuit breaker configuration suitable
+// for most applications.
+//
+// The circuit opens after 5 consecutive failures, stays open for 30 seconds
+// and closes after one successful trial request.
+func DefaultCircuitBreakerConfig() CircuitBreakerConfig {
+	return CircuitBreakerConfig{
+		FailureThreshold: 5,
+		CoolDown:         30 * time.Second,
+		SuccessThreshold: 1,
+	}
+}
+
+// circuitBreaker tracks the outcome of all requests of a client.
+//
+// Every request attempt, including retries, asks the breaker for permission
+// and reports its outcome. The generation changes on every state transition
+// so that outcomes of requests started in an earlier state are ignored.
+type circuitBreaker struct {
+	config CircuitBreakerConfig
+	now    func() time.Time
+
+	mu         sync.Mutex
+	state      CircuitState
+	generation uint64
+	failures   int
+	successes  int
+	openedAt   time.Time
+	trial      bool
+}
+
+type circuitTransition struct {
+	from, to CircuitState
+}
+
+func newCircuitBreaker(config CircuitBreakerConfig) *circuitBreaker {
+	if config.FailureThreshold < 1 {
+		config.FailureThreshold = 1
+	}
+	if config.SuccessThreshold < 1 {
+		config.SuccessThreshold = 1
+	}
+	if config.IsFailure == nil {
+		config.IsFailure = isCircuitFailure
+	}
+	return &circuitBreaker{config: config, now: time.Now}
+}
+
+// isCircuitFailure is the default CircuitBreakerConfig.IsFailure.
+func isCircuitFailure(err error) bool {
+	return errors.Is(err, ErrServerError) || errors.Is(err, ErrTimeout) || errors.Is(err, ErrNetwork)
+}
+
+// allow returns ErrCircuitOpen if a request may not be sent. Otherwise it
+// returns a function that must be called once with the outcome of the request.
+func (b *circuitBreaker) allow() (func(err error), error) {
+	b.mu.Lock()
+	transitions := b.advance(b.now())
+	allowed := true
+	switch b.state {
+	case CircuitOpen:
+		allowed = false
+	case CircuitHalfOpen:
+		if b.trial {
+			allowed = false
+		} else {
+			b.trial = true
+		}
+	}
+	generation := b.generation
+	b.mu.Unlock()
+	b.notify(transitions)
+
+	if !allowed {
+		return nil, ErrCircuitOpen
+	}
+	var once sync.Once
+	return func(err error) {
+		once.Do(func() { b.report(generation, err) })
+	}, nil
+}
+
+// report records the outcome of a request allowed in the given generation.
+func (b *circuitBreaker) report(generation uint64, err error) {
+	b.mu.Lock()
+	if generation != b.generation {
+		b.mu.Unlock()
+		return
+	}
+
+	var transitions []circuitTransition
+	if b.state == CircuitHalfOpen {
+		b.trial = false
+	}
+	switch {
+	case errors.Is(err, context.Canceled):
+	case err != nil && b.config.IsFailure(err):
+		b.successes = 0
+		b.failures++
+		if b.state == CircuitHalfOpen || b.failures >= b.config.FailureThreshold {
+			transitions = append(transitions, b.transition(CircuitOpen, b.now()))
+		}
+	default:
+		b.failures = 0
+		if b.state == CircuitHalfOpen {
+			b.successes++
+			if b.successes >= b.config.SuccessThreshold {
+				transitions = append(transitions, b.transition(CircuitClosed, b.now()))
+			}
+		}
+	}
+	b.mu.Unlock()
+	b.notify(transitions)
+}
+
+// current returns the state of the circuit.
+func (b *circuitBreaker) current() CircuitState {
+	b.mu.Lock()
+	transitions := b.advance(b.now())
+	state := b.state
+	b.mu.Unlock()
+	b.notify(transitions)
+	return state
+}
+
+// advance moves an open circuit to half-open once the cool-down has elapsed.
+func (b *circuitBreaker) advance(now time.Time) []circuitTransition {
+	if b.state == CircuitOpen && !now.Before(b.openedAt.Add(b.config.CoolDown)) {
+		return []circuitTransition{b.transition(CircuitHalfOpen, now)}
+	}
+	return nil
+}
+
+func (b *circuitBreaker) transition(to CircuitState, now time.Time) circuitTransition {
+	from := b.state
+	b.state = to
+	b.generation++
+	b.failures = 0
+	b.successes = 0
+	b.trial = false
+	if to == CircuitOpen {
+		b.openedAt = now
+	}
+	return circuitTransition{from: from, to: to}
+}
+
+func (b *circuitBreaker) notify(transitions []circuitTransition) {
+	if b.config.OnStateChange == nil {
+		return
+	}
+	for _, t := range transitions {
+		b.config.OnStateChange(t.from, t.to)
+	}
+}
+
+// CircuitState returns the state of the client's circuit breaker.
+// It is always CircuitClosed when no circuit breaker is configured.
+func (c *Client) CircuitState() CircuitState {
+	if c.circuitBreaker == nil {
+		return CircuitClosed
+	}
+	return c.circuitBreaker.current()
+}
+
+// CircuitState returns the state of the client's circuit breaker.
+// It is always CircuitClosed when no circuit breaker is configured.
+func (api *APIClient) CircuitState() CircuitState {
+	return api.client.CircuitState()
+}
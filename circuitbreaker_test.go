@@ -0,0 +1,168 @@
+package lettermint
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWithCircuitBreaker_OpensAndRecovers(t *testing.T) {
+	var requests atomic.Int32
+	var healthy atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte(`{"message":"Service unavailable"}`))
+			return
+		}
+		_, _ = w.Write([]byte("pong"))
+	}))
+	defer server.Close()
+
+	var mu sync.Mutex
+	var transitions []string
+	config := CircuitBreakerConfig{
+		FailureThreshold: 3,
+		CoolDown:         20 * time.Millisecond,
+		SuccessThreshold: 1,
+		OnStateChange: func(from, to CircuitState) {
+			mu.Lock()
+			transitions = append(transitions, from.String()+"->"+to.String())
+			mu.Unlock()
+		},
+	}
+	api, _ := NewAPI("api-token", WithBaseURL(server.URL), WithCircuitBreaker(config))
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		if _, err := api.Ping(ctx); !errors.Is(err, ErrServerError) {
+			t.Fatalf("Ping() #%d error = %v, want ErrServerError", i+1, err)
+		}
+	}
+	if api.CircuitState() != CircuitOpen {
+		t.Fatalf("CircuitState() = %v, want open", api.CircuitState())
+	}
+
+	_, err := api.Ping(ctx)
+	if !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Ping() error = %v, want ErrCircuitOpen", err)
+	}
+	if !IsRetryable(err) {
+		t.Fatal("IsRetryable(ErrCircuitOpen) = false")
+	}
+	if got := requests.Load(); got != 3 {
+		t.Fatalf("server received %d requests, want 3", got)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	healthy.Store(true)
+	if _, err := api.Ping(ctx); err != nil {
+		t.Fatalf("trial Ping() error = %v", err)
+	}
+	if api.CircuitState() != CircuitClosed {
+		t.Fatalf("CircuitState() = %v, want closed", api.CircuitState())
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := []string{"closed->open", "open->half-open", "half-open->closed"}
+	if len(transitions) != len(want) {
+		t.Fatalf("transitions = %v, want %v", transitions, want)
+	}
+	for i := range want {
+		if transitions[i] != want[i] {
+			t.Fatalf("transitions = %v, want %v", transitions, want)
+		}
+	}
+}
+
+func TestWithCircuitBreaker_IgnoresClientErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"message":"Not found"}`))
+	}))
+	defer server.Close()
+
+	config := DefaultCircuitBreakerConfig()
+	config.FailureThreshold = 1
+	api, _ := NewAPI("api-token", WithBaseURL(server.URL), WithCircuitBreaker(config))
+	for i := 0; i < 3; i++ {
+		if _, err := api.Domains.Retrieve(context.Background(), "missing"); !errors.Is(err, ErrNotFound) {
+			t.Fatalf("Domains.Retrieve() error = %v, want ErrNotFound", err)
+		}
+	}
+	if api.CircuitState() != CircuitClosed {
+		t.Fatalf("CircuitState() = %v, want closed", api.CircuitState())
+	}
+}
+
+func TestCircuitBreaker_HalfOpen(t *testing.T) {
+	breaker := newCircuitBreaker(CircuitBreakerConfig{FailureThreshold: 2, CoolDown: time.Minute, SuccessThreshold: 2})
+	now := time.Now()
+	breaker.now = func() time.Time { return now }
+	failure := &APIError{StatusCode: 500}
+
+	for i := 0; i < 2; i++ {
+		report, err := breaker.allow()
+		if err != nil {
+			t.Fatalf("allow() error = %v", err)
+		}
+		report(failure)
+	}
+	if _, err := breaker.allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("allow() error = %v, want ErrCircuitOpen", err)
+	}
+
+	now = now.Add(time.Minute)
+	trial, err := breaker.allow()
+	if err != nil {
+		t.Fatalf("trial allow() error = %v", err)
+	}
+	if _, err := breaker.allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("concurrent trial allow() error = %v, want ErrCircuitOpen", err)
+	}
+
+	// A failed trial opens the circuit again for another cool-down.
+	trial(failure)
+	now = now.Add(time.Second)
+	if state := breaker.current(); state != CircuitOpen {
+		t.Fatalf("state = %v, want open", state)
+	}
+
+	now = now.Add(time.Minute)
+	for i := 0; i < 2; i++ {
+		if state := breaker.current(); state != CircuitHalfOpen {
+			t.Fatalf("state = %v, want half-open", state)
+		}
+		trial, err := breaker.allow()
+		if err != nil {
+			t.Fatalf("trial allow() error = %v", err)
+		}
+		trial(nil)
+	}
+	if state := breaker.current(); state != CircuitClosed {
+		t.Fatalf("state = %v, want closed", state)
+	}
+}
+
+func TestCircuitBreaker_IgnoresCanceledRequests(t *testing.T) {
+	breaker := newCircuitBreaker(CircuitBreakerConfig{FailureThreshold: 1, CoolDown: time.Minute})
+
+	report, _ := breaker.allow()
+	report(transportError(canceledContext(), context.Canceled))
+	if state := breaker.current(); state != CircuitClosed {
+		t.Fatalf("state = %v, want closed", state)
+	}
+}
+
+func canceledContext() context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	return ctx
+}
@@ -0,0 +1,101 @@
+package lettermint
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io"
+	"net"
+	"syscall"
+)
+
+// IsRetryable reports whether err is a transient failure, so that sending the
+// same request again later may succeed.
+//
+// Retryable errors are API errors with a status code retried by
+// DefaultRetryPolicy (408, 429, 500, 502, 503 and 504), timeouts, network
+// errors such as failed DNS lookups or refused connections, responses that
+// were cut off while being read, and ErrCircuitOpen.
+//
+// Errors caused by canceling the request context are neither retryable nor
+// permanent: the request was abandoned by the caller, not rejected.
+func IsRetryable(err error) bool {
+	return classify(err) == errorRetryable
+}
+
+// IsPermanent reports whether err is a failure that will occur again when
+// the same request is retried, such as a validation error, a missing
+// resource, invalid credentials, an unknown host or a TLS certificate that
+// cannot be verified.
+//
+// An error may be neither retryable nor permanent, e.g. when the request
+// context was canceled or when the error did not originate from this package.
+func IsPermanent(err error) bool {
+	return classify(err) == errorPermanent
+}
+
+type errorClass int
+
+const (
+	errorUnknown errorClass = iota
+	errorRetryable
+	errorPermanent
+)
+
+func classify(err error) errorClass {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return errorUnknown
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		if DefaultRetryPolicy().retryableStatus(apiErr.StatusCode) {
+			return errorRetryable
+		}
+		return errorPermanent
+	}
+
+	switch {
+	case errors.Is(err, ErrTimeout), errors.Is(err, context.DeadlineExceeded), errors.Is(err, ErrCircuitOpen):
+		return errorRetryable
+	case errors.Is(err, ErrInvalidAPIToken),
+		errors.Is(err, ErrInvalidRequest),
+		errors.Is(err, ErrResponseTooLarge),
+		errors.Is(err, ErrInvalidWebhookSignature),
+		errors.Is(err, ErrWebhookTimestampExpired):
+		return errorPermanent
+	}
+
+	var certErr *tls.CertificateVerificationError
+	var authorityErr x509.UnknownAuthorityError
+	var hostnameErr x509.HostnameError
+	var invalidErr x509.CertificateInvalidError
+	if errors.As(err, &certErr) || errors.As(err, &authorityErr) || errors.As(err, &hostnameErr) || errors.As(err, &invalidErr) {
+		return errorPermanent
+	}
+
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		if dnsErr.IsNotFound {
+			return errorPermanent
+		}
+		return errorRetryable
+	}
+
+	var netErr net.Error
+	var opErr *net.OpError
+	switch {
+	case errors.As(err, &netErr) && netErr.Timeout(),
+		errors.As(err, &opErr),
+		errors.Is(err, io.ErrUnexpectedEOF),
+		errors.Is(err, io.EOF),
+		errors.Is(err, syscall.ECONNREFUSED),
+		errors.Is(err, syscall.ECONNRESET),
+		errors.Is(err, syscall.EPIPE),
+		errors.Is(err, ErrNetwork):
+		return errorRetryable
+	}
+
+	return errorUnknown
+}
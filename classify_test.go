@@ -0,0 +1,126 @@
+package lettermint
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"syscall"
+	"testing"
+)
+
+func TestIsRetryable_IsPermanent(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		retryable bool
+		permanent bool
+	}{
+		{name: "nil", err: nil},
+		{name: "429", err: &APIError{StatusCode: 429}, retryable: true},
+		{name: "503", err: &APIError{StatusCode: 503}, retryable: true},
+		{name: "408", err: &APIError{StatusCode: 408}, retryable: true},
+		{name: "400", err: &APIError{StatusCode: 400}, permanent: true},
+		{name: "404", err: &APIError{StatusCode: 404}, permanent: true},
+		{name: "422 wrapped", err: fmt.Errorf("send: %w", &APIError{StatusCode: 422}), permanent: true},
+		{name: "501", err: &APIError{StatusCode: 501}, permanent: true},
+		{name: "timeout", err: fmt.Errorf("%w: %w", ErrTimeout, context.DeadlineExceeded), retryable: true},
+		{name: "deadline", err: context.DeadlineExceeded, retryable: true},
+		{name: "canceled", err: fmt.Errorf("lettermint: request canceled: %w", context.Canceled)},
+		{name: "invalid request", err: fmt.Errorf("%w: missing from", ErrInvalidRequest), permanent: true},
+		{name: "invalid token", err: ErrInvalidAPIToken, permanent: true},
+		{name: "dns not found", err: &net.DNSError{Err: "no such host", Name: "api.invalid", IsNotFound: true}, permanent: true},
+		{name: "dns temporary", err: &net.DNSError{Err: "server misbehaving", Name: "api.lettermint.co", IsTemporary: true}, retryable: true},
+		{name: "dial refused", err: &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}, retryable: true},
+		{name: "connection reset", err: fmt.Errorf("read: %w", syscall.ECONNRESET), retryable: true},
+		{name: "truncated read", err: fmt.Errorf("%w: failed to read response: %w", ErrNetwork, io.ErrUnexpectedEOF), retryable: true},
+		{name: "unrelated", err: errors.New("boom")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsRetryable(tt.err); got != tt.retryable {
+				t.Errorf("IsRetryable() = %v, want %v", got, tt.retryable)
+			}
+			if got := IsPermanent(tt.err); got != tt.permanent {
+				t.Errorf("IsPermanent() = %v, want %v", got, tt.permanent)
+			}
+		})
+	}
+}
+
+func TestIsRetryable_DialError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	url := server.URL
+	server.Close()
+
+	api, _ := NewAPI("api-token", WithBaseURL(url), WithRetryPolicy(RetryPolicy{MaxAttempts: 1}))
+	_, err := api.Ping(context.Background())
+	if !errors.Is(err, ErrNetwork) {
+		t.Fatalf("Ping() error = %v, want ErrNetwork", err)
+	}
+	if !IsRetryable(err) || IsPermanent(err) {
+		t.Fatalf("IsRetryable() = %v, IsPermanent() = %v for %v", IsRetryable(err), IsPermanent(err), err)
+	}
+}
+
+func TestIsRetryable_TruncatedResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", "100")
+		_, _ = w.Write([]byte(`{"data":`))
+	}))
+	defer server.Close()
+
+	api, _ := NewAPI("api-token", WithBaseURL(server.URL))
+	_, err := api.Domains.List(context.Background(), nil)
+	if !errors.Is(err, io.ErrUnexpectedEOF) || !errors.Is(err, ErrNetwork) {
+		t.Fatalf("Domains.List() error = %v, want truncated read", err)
+	}
+	if !IsRetryable(err) {
+		t.Fatalf("IsRetryable(%v) = false", err)
+	}
+}
+
+func TestIsRetryable_CanceledRequest(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cancel()
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	api, _ := NewAPI("api-token", WithBaseURL(server.URL))
+	_, err := api.Ping(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Ping() error = %v, want context.Canceled", err)
+	}
+	if IsRetryable(err) || IsPermanent(err) {
+		t.Fatalf("IsRetryable() = %v, IsPermanent() = %v for canceled request", IsRetryable(err), IsPermanent(err))
+	}
+}
+
+func TestRetry_TruncatedErrorResponse(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			w.Header().Set("Content-Length", "100")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte(`{"message":`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":[]}`))
+	}))
+	defer server.Close()
+
+	api, _ := NewAPI("api-token", WithBaseURL(server.URL), WithRetryPolicy(testRetryPolicy()))
+	if _, err := api.Domains.List(context.Background(), nil); err != nil {
+		t.Fatalf("Domains.List() error = %v", err)
+	}
+	if attempts != 2 {
+		t.Fatalf("attempts = %d, want 2", attempts)
+	}
+}
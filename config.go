@@ -0,0 +1,440 @@
+package lettermint
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Environment variables read by NewFromEnv, NewAPIFromEnv and LoadConfig.
+const (
+	// EnvAPIToken holds the API token.
+	EnvAPIToken = "LETTERMINT_API_TOKEN"
+
+	// EnvBaseURL holds the API base URL.
+	EnvBaseURL = "LETTERMINT_BASE_URL"
+
+	// EnvTimeout holds the HTTP client timeout as a duration ("10s") or
+	// a number of seconds ("10").
+	EnvTimeout = "LETTERMINT_TIMEOUT"
+
+	// EnvRetryMaxAttempts holds the maximum number of attempts per request.
+	EnvRetryMaxAttempts = "LETTERMINT_RETRY_MAX_ATTEMPTS"
+
+	// EnvRetryInitialBackoff holds the delay before the first retry.
+	EnvRetryInitialBackoff = "LETTERMINT_RETRY_INITIAL_BACKOFF"
+
+	// EnvRetryMaxBackoff holds the maximum delay between retries.
+	EnvRetryMaxBackoff = "LETTERMINT_RETRY_MAX_BACKOFF"
+
+	// EnvProfile selects the profile to load from the config file.
+	EnvProfile = "LETTERMINT_PROFILE"
+
+	// EnvConfigFile overrides the location of the config file.
+	EnvConfigFile = "LETTERMINT_CONFIG_FILE"
+)
+
+// DefaultProfile is the profile loaded from the config file when
+// LETTERMINT_PROFILE is not set.
+const DefaultProfile = "default"
+
+// Config holds client settings loaded from the environment or a config file.
+//
+// Zero values mean the setting was not configured and the client default
+// applies.
+type Config struct {
+	// Profile is the name of the profile the settings were loaded from,
+	// or empty if no config file was used.
+	Profile string
+
+	// APIToken is the API token.
+	APIToken string
+
+	// BaseURL is the API base URL.
+	BaseURL string
+
+	// Timeout is the HTTP client timeout.
+	Timeout time.Duration
+
+	// RetryMaxAttempts is the maximum number of attempts per request.
+	// Retries are enabled with DefaultRetryPolicy() as soon as any of the
+	// retry settings is configured.
+	RetryMaxAttempts int
+
+	// RetryInitialBackoff is the delay before the first retry.
+	RetryInitialBackoff time.Duration
+
+	// RetryMaxBackoff is the maximum delay between retries.
+	RetryMaxBackoff time.Duration
+}
+
+// NewFromEnv creates a new sending client configured from the environment.
+//
+// Settings are read from the config file profile selected by
+// LETTERMINT_PROFILE, if any, and from the LETTERMINT_* environment
+// variables, which take precedence. See LoadConfig for details.
+// The given options are applied last and override both.
+//
+// Example:
+//
+//	// LETTERMINT_API_TOKEN=... LETTERMINT_TIMEOUT=10s
+//	client, err := lettermint.NewFromEnv()
+func NewFromEnv(opts ...Option) (*Client, error) {
+	config, err := LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+	return New(config.APIToken, append(config.Options(), opts...)...)
+}
+
+// NewAPIFromEnv creates a new Team API client configured from the
+// environment, like NewFromEnv.
+func NewAPIFromEnv(opts ...Option) (*APIClient, error) {
+	config, err := LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+	return NewAPI(config.APIToken, append(config.Options(), opts...)...)
+}
+
+// LoadConfig loads the client settings from the config file and the
+// environment.
+//
+// The config file is read from LETTERMINT_CONFIG_FILE or, if unset, from
+// $XDG_CONFIG_HOME/lettermint/config.toml, falling back to
+// ~/.config/lettermint/config.toml. A missing default config file is not an
+// error. The profile is selected with LETTERMINT_PROFILE and defaults to
+// DefaultProfile.
+//
+// The LETTERMINT_* environment variables override the settings of the profile.
+func LoadConfig() (Config, error) {
+	var config Config
+
+	path := os.Getenv(EnvConfigFile)
+	explicitPath := path != ""
+	if !explicitPath {
+		path = defaultConfigPath()
+	}
+	profile := os.Getenv(EnvProfile)
+	explicitProfile := profile != ""
+	if !explicitProfile {
+		profile = DefaultProfile
+	}
+
+	errNoConfigFile := fmt.Errorf("%w: %s is set but no config file was found", ErrInvalidConfig, EnvProfile)
+	if path != "" {
+		loaded, err := LoadConfigFile(path, profile)
+		switch {
+		case err == nil:
+			config = loaded
+		case errors.Is(err, os.ErrNotExist) && !explicitPath:
+			if explicitProfile {
+				return Config{}, errNoConfigFile
+			}
+		case errors.Is(err, errProfileNotFound) && !explicitProfile:
+			// Without a default profile, only the shared settings apply.
+			if config, err = LoadConfigFile(path, ""); err != nil {
+				return Config{}, err
+			}
+			config.Profile = ""
+		default:
+			return Config{}, err
+		}
+	} else if explicitProfile {
+		return Config{}, errNoConfigFile
+	}
+
+	if err := config.applyEnv(); err != nil {
+		return Config{}, err
+	}
+	return config, nil
+}
+
+// LoadConfigFile loads the settings of the named profile from a TOML config
+// file.
+//
+// Each profile is a table. Keys outside of a table are shared by all
+// profiles:
+//
+//	timeout = "15s"
+//
+//	[staging]
+//	api_token = "lm_staging_..."
+//	base_url = "https://staging.example.com/v1"
+//
+//	[prod]
+//	api_token = "lm_prod_..."
+//	retry_max_attempts = 5
+//	retry_initial_backoff = "1s"
+//
+// Supported keys are api_token, base_url, timeout, retry_max_attempts,
+// retry_initial_backoff and retry_max_backoff. Durations are strings such as
+// "10s" or integers in seconds.
+func LoadConfigFile(path, profile string) (Config, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return Config{}, fmt.Errorf("lettermint: failed to open config file: %w", err)
+	}
+	defer file.Close()
+
+	tables, err := parseConfigTables(file)
+	if err != nil {
+		return Config{}, fmt.Errorf("%w: %s: %v", ErrInvalidConfig, path, err)
+	}
+	values, ok := tables[profile]
+	if !ok {
+		return Config{}, fmt.Errorf("%w: %s: %w %q", ErrInvalidConfig, path, errProfileNotFound, profile)
+	}
+
+	config := Config{Profile: profile}
+	for _, table := range []map[string]string{tables[""], values} {
+		for key, value := range table {
+			if err := config.set(configKeys[key], key, value); err != nil {
+				return Config{}, fmt.Errorf("%w: %s: %v", ErrInvalidConfig, path, err)
+			}
+		}
+	}
+	return config, nil
+}
+
+// Options returns the client options for the configured settings.
+func (c Config) Options() []Option {
+	var opts []Option
+	if c.BaseURL != "" {
+		opts = append(opts, WithBaseURL(c.BaseURL))
+	}
+	if c.Timeout > 0 {
+		opts = append(opts, WithTimeout(c.Timeout))
+	}
+	if c.RetryMaxAttempts > 0 || c.RetryInitialBackoff > 0 || c.RetryMaxBackoff > 0 {
+		policy := DefaultRetryPolicy()
+		if c.RetryMaxAttempts > 0 {
+			policy.MaxAttempts = c.RetryMaxAttempts
+		}
+		if c.RetryInitialBackoff > 0 {
+			policy.InitialBackoff = c.RetryInitialBackoff
+		}
+		if c.RetryMaxBackoff > 0 {
+			policy.MaxBackoff = c.RetryMaxBackoff
+		}
+		opts = append(opts, WithRetryPolicy(policy))
+	}
+	return opts
+}
+
+var errProfileNotFound = errors.New("profile not found")
+
+// configKeys maps config file keys to environment variables.
+var configKeys = map[string]string{
+	"api_token":             EnvAPIToken,
+	"base_url":              EnvBaseURL,
+	"timeout":               EnvTimeout,
+	"retry_max_attempts":    EnvRetryMaxAttempts,
+	"retry_initial_backoff": EnvRetryInitialBackoff,
+	"retry_max_backoff":     EnvRetryMaxBackoff,
+}
+
+func (c *Config) applyEnv() error {
+	for _, env := range []string{EnvAPIToken, EnvBaseURL, EnvTimeout, EnvRetryMaxAttempts, EnvRetryInitialBackoff, EnvRetryMaxBackoff} {
+		value := os.Getenv(env)
+		if value == "" {
+			continue
+		}
+		if err := c.set(env, env, value); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidConfig, err)
+		}
+	}
+	return nil
+}
+
+// set assigns the setting identified by its environment variable. name is
+// the variable or config file key used in error messages.
+func (c *Config) set(env, name, value string) error {
+	var err error
+	switch env {
+	case EnvAPIToken:
+		c.APIToken = value
+	case EnvBaseURL:
+		c.BaseURL = value
+	case EnvTimeout:
+		c.Timeout, err = parseConfigDuration(value)
+	case EnvRetryMaxAttempts:
+		c.RetryMaxAttempts, err = strconv.Atoi(value)
+		if err == nil && c.RetryMaxAttempts < 1 {
+			err = errors.New("must be at least 1")
+		}
+	case EnvRetryInitialBackoff:
+		c.RetryInitialBackoff, err = parseConfigDuration(value)
+	case EnvRetryMaxBackoff:
+		c.RetryMaxBackoff, err = parseConfigDuration(value)
+	default:
+		return fmt.Errorf("unknown key %q", name)
+	}
+	if err != nil {
+		return fmt.Errorf("invalid %s %q: %v", name, value, err)
+	}
+	return nil
+}
+
+// parseConfigDuration parses a duration such as "10s", or a number of seconds.
+func parseConfigDuration(value string) (time.Duration, error) {
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+		if seconds < 0 {
+			return 0, errors.New("must not be negative")
+		}
+		return time.Duration(seconds * float64(time.Second)), nil
+	}
+	d, err := time.ParseDuration(value)
+	if err == nil && d < 0 {
+		return 0, errors.New("must not be negative")
+	}
+	return d, err
+}
+
+// defaultConfigPath returns the default location of the config file, or an
+// empty string if it cannot be determined.
+func defaultConfigPath() string {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "lettermint", "config.toml")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config", "lettermint", "config.toml")
+}
+
+// parseConfigTables parses the subset of TOML used by config files: tables,
+// and key/value pairs with string, integer, float or boolean values.
+// Values are returned in their string form, keyed by table name. Keys outside
+// of a table are stored under the empty table name.
+func parseConfigTables(r io.Reader) (map[string]map[string]string, error) {
+	tables := map[string]map[string]string{"": {}}
+	table := ""
+
+	scanner := bufio.NewScanner(r)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			end := strings.Index(line, "]")
+			if end < 0 || strings.HasPrefix(line, "[[") || !isConfigComment(line[end+1:]) {
+				return nil, fmt.Errorf("line %d: invalid table header", lineNo)
+			}
+			name, err := parseConfigKey(strings.TrimSpace(line[1:end]))
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %v", lineNo, err)
+			}
+			if _, ok := tables[name]; ok {
+				return nil, fmt.Errorf("line %d: duplicate table %q", lineNo, name)
+			}
+			table = name
+			tables[table] = map[string]string{}
+			continue
+		}
+
+		eq := strings.Index(line, "=")
+		if eq < 0 {
+			return nil, fmt.Errorf("line %d: expected key = value", lineNo)
+		}
+		key, err := parseConfigKey(strings.TrimSpace(line[:eq]))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", lineNo, err)
+		}
+		value, err := parseConfigValue(strings.TrimSpace(line[eq+1:]))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", lineNo, err)
+		}
+		if _, ok := tables[table][key]; ok {
+			return nil, fmt.Errorf("line %d: duplicate key %q", lineNo, key)
+		}
+		tables[table][key] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return tables, nil
+}
+
+func parseConfigKey(key string) (string, error) {
+	if len(key) >= 2 && (key[0] == '"' || key[0] == '\'') {
+		value, rest, err := parseConfigString(key)
+		if err != nil || rest != "" {
+			return "", fmt.Errorf("invalid key %s", key)
+		}
+		return value, nil
+	}
+	if key == "" {
+		return "", errors.New("empty key")
+	}
+	for _, r := range key {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '-') {
+			return "", fmt.Errorf("invalid key %q", key)
+		}
+	}
+	return key, nil
+}
+
+func parseConfigValue(raw string) (string, error) {
+	if strings.HasPrefix(raw, `"`) || strings.HasPrefix(raw, "'") {
+		value, rest, err := parseConfigString(raw)
+		if err != nil {
+			return "", err
+		}
+		if !isConfigComment(rest) {
+			return "", fmt.Errorf("unexpected %q after string", rest)
+		}
+		return value, nil
+	}
+
+	if i := strings.Index(raw, "#"); i >= 0 {
+		raw = strings.TrimSpace(raw[:i])
+	}
+	if raw == "true" || raw == "false" {
+		return raw, nil
+	}
+	number := strings.ReplaceAll(raw, "_", "")
+	if _, err := strconv.ParseFloat(number, 64); err != nil || number == "" {
+		return "", fmt.Errorf("unsupported value %q", raw)
+	}
+	return number, nil
+}
+
+// parseConfigString parses a basic ("...") or literal ('...') string at the
+// start of raw and returns the remainder.
+func parseConfigString(raw string) (string, string, error) {
+	if raw[0] == '\'' {
+		end := strings.IndexByte(raw[1:], '\'')
+		if end < 0 {
+			return "", "", errors.New("unterminated string")
+		}
+		return raw[1 : end+1], raw[end+2:], nil
+	}
+	for i := 1; i < len(raw); i++ {
+		switch raw[i] {
+		case '\\':
+			i++
+		case '"':
+			value, err := strconv.Unquote(raw[:i+1])
+			if err != nil {
+				return "", "", fmt.Errorf("invalid string %s", raw[:i+1])
+			}
+			return value, raw[i+1:], nil
+		}
+	}
+	return "", "", errors.New("unterminated string")
+}
+
+func isConfigComment(rest string) bool {
+	rest = strings.TrimSpace(rest)
+	return rest == "" || strings.HasPrefix(rest, "#")
+}
@@ -0,0 +1,180 @@
+package lettermint
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// clearConfigEnv isolates a test from the LETTERMINT_* variables and config
+// file of the environment running the tests.
+func clearConfigEnv(t *testing.T) {
+	t.Helper()
+	for _, env := range []string{EnvAPIToken, EnvBaseURL, EnvTimeout, EnvRetryMaxAttempts, EnvRetryInitialBackoff, EnvRetryMaxBackoff, EnvProfile, EnvConfigFile} {
+		t.Setenv(env, "")
+	}
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+}
+
+func writeConfigFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+const testConfigFile = `
+# Shared settings
+timeout = "15s"
+
+[staging]
+api_token = "staging-token" # inline comment
+base_url = 'https://staging.example.com/v1'
+
+[prod]
+api_token = "prod-token"
+retry_max_attempts = 5
+retry_initial_backoff = 2
+`
+
+func TestNewAPIFromEnv(t *testing.T) {
+	clearConfigEnv(t)
+
+	var gotAuth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		_, _ = w.Write([]byte("pong"))
+	}))
+	defer server.Close()
+
+	t.Setenv(EnvAPIToken, "env-token")
+	t.Setenv(EnvBaseURL, server.URL)
+	t.Setenv(EnvTimeout, "5s")
+
+	api, err := NewAPIFromEnv()
+	if err != nil {
+		t.Fatalf("NewAPIFromEnv() error = %v", err)
+	}
+	if api.client.httpClient.Timeout != 5*time.Second {
+		t.Errorf("Timeout = %v, want 5s", api.client.httpClient.Timeout)
+	}
+	if _, err := api.Ping(context.Background()); err != nil {
+		t.Fatalf("Ping() error = %v", err)
+	}
+	if gotAuth != "Bearer env-token" {
+		t.Errorf("Authorization = %q, want Bearer env-token", gotAuth)
+	}
+}
+
+func TestNewFromEnv_MissingToken(t *testing.T) {
+	clearConfigEnv(t)
+
+	if _, err := NewFromEnv(); !errors.Is(err, ErrInvalidAPIToken) {
+		t.Fatalf("NewFromEnv() error = %v, want ErrInvalidAPIToken", err)
+	}
+}
+
+func TestNewFromEnv_Profile(t *testing.T) {
+	clearConfigEnv(t)
+	t.Setenv(EnvConfigFile, writeConfigFile(t, testConfigFile))
+	t.Setenv(EnvProfile, "prod")
+	t.Setenv(EnvRetryMaxBackoff, "10s")
+
+	client, err := NewFromEnv(WithTimeout(time.Second))
+	if err != nil {
+		t.Fatalf("NewFromEnv() error = %v", err)
+	}
+	if token, _ := client.tokenSource.Token(context.Background()); token != "prod-token" {
+		t.Errorf("token = %q, want prod-token", token)
+	}
+	if client.httpClient.Timeout != time.Second {
+		t.Errorf("Timeout = %v, want explicit option to win", client.httpClient.Timeout)
+	}
+	policy := client.retryPolicy
+	if policy.MaxAttempts != 5 || policy.InitialBackoff != 2*time.Second || policy.MaxBackoff != 10*time.Second {
+		t.Errorf("retryPolicy = %+v", policy)
+	}
+}
+
+func TestLoadConfig_DefaultFileLocation(t *testing.T) {
+	clearConfigEnv(t)
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	if err := os.MkdirAll(filepath.Join(dir, "lettermint"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "lettermint", "config.toml"), []byte(testConfigFile), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// Without a default profile only the shared settings apply.
+	config, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if config.Profile != "" || config.APIToken != "" || config.Timeout != 15*time.Second {
+		t.Errorf("config = %+v", config)
+	}
+
+	t.Setenv(EnvProfile, "staging")
+	t.Setenv(EnvAPIToken, "override-token")
+	config, err = LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	want := Config{Profile: "staging", APIToken: "override-token", BaseURL: "https://staging.example.com/v1", Timeout: 15 * time.Second}
+	if config != want {
+		t.Errorf("config = %+v, want %+v", config, want)
+	}
+}
+
+func TestLoadConfig_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		env     map[string]string
+		wantErr string
+	}{
+		{name: "unknown profile", file: testConfigFile, env: map[string]string{EnvProfile: "dev"}, wantErr: `profile not found "dev"`},
+		{name: "profile without file", env: map[string]string{EnvProfile: "prod"}, wantErr: "no config file"},
+		{name: "invalid timeout", env: map[string]string{EnvTimeout: "soon"}, wantErr: "invalid LETTERMINT_TIMEOUT"},
+		{name: "invalid attempts", env: map[string]string{EnvRetryMaxAttempts: "0"}, wantErr: "must be at least 1"},
+		{name: "unknown key", file: "[default]\napi_key = \"x\"\n", wantErr: `unknown key "api_key"`},
+		{name: "syntax error", file: "[default\n", wantErr: "line 1: invalid table header"},
+		{name: "duplicate key", file: "timeout = 1\ntimeout = 2\n", wantErr: `line 2: duplicate key "timeout"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearConfigEnv(t)
+			if tt.file != "" {
+				t.Setenv(EnvConfigFile, writeConfigFile(t, tt.file))
+			}
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			_, err := LoadConfig()
+			if !errors.Is(err, ErrInvalidConfig) || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("LoadConfig() error = %v, want ErrInvalidConfig containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadConfig_MissingExplicitFile(t *testing.T) {
+	clearConfigEnv(t)
+	t.Setenv(EnvConfigFile, filepath.Join(t.TempDir(), "missing.toml"))
+
+	if _, err := LoadConfig(); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("LoadConfig() error = %v, want os.ErrNotExist", err)
+	}
+}
@@ -0,0 +1,764 @@
+package lettermint
+
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+)
+
+// InlineCSS moves the rules of the <style> blocks of an HTML document into
+// the style attributes of the elements they match, because many email
+// clients ignore <style> blocks.
+//
+// Existing style attributes take precedence over the stylesheet unless a
+// rule is marked !important, as in a browser. Rules that cannot be inlined,
+// such as media queries, @font-face rules and selectors with pseudo-classes
+// like :hover, are kept in a <style> block in the document head for the
+// clients that support them. The selectors of those rules are returned as
+// unsupported, except for at-rules, which are never inlined.
+//
+// Supported selectors are type, universal, class, ID and attribute
+// selectors, the :first-child, :last-child and :only-child pseudo-classes,
+// and the descendant, child (>), next-sibling (+) and subsequent-sibling
+// (~) combinators. <style> blocks with a media attribute other than "all"
+// or "screen" are left as is.
+func InlineCSS(html string) (inlined string, unsupported []string) {
+	doc := parseHTML(html)
+
+	var styles []*htmlNode
+	doc.walk(func(n *htmlNode) bool {
+		if n.Type == htmlElementNode && n.Tag == "style" {
+			if media, _ := n.attr("media"); media == "" || strings.EqualFold(media, "all") || strings.EqualFold(media, "screen") {
+				styles = append(styles, n)
+			}
+			return false
+		}
+		return true
+	})
+	if len(styles) == 0 {
+		return html, nil
+	}
+
+	var sheet cssStyleSheet
+	for _, style := range styles {
+		var css strings.Builder
+		for _, child := range style.Children {
+			css.WriteString(child.Data)
+		}
+		sheet.parse(css.String())
+		style.Parent.removeChild(style)
+	}
+
+	sheet.apply(doc)
+	if len(sheet.kept) > 0 {
+		insertHeadStyle(doc, strings.Join(sheet.kept, "\n"))
+	}
+	return renderHTML(doc), sheet.unsupported
+}
+
+// InlineCSSOption configures EmailBuilder.InlineCSS.
+type InlineCSSOption func(*inlineCSSOptions)
+
+type inlineCSSOptions struct {
+	onUnsupported func(selectors []string)
+}
+
+// OnUnsupportedCSS calls fn with the selectors that could not be inlined
+// when the email is built or sent, so that they can be reported or
+// rejected. fn is not called if every rule was inlined.
+//
+// Example:
+//
+//	client.Email(ctx).
+//	    HTML(body).
+//	    InlineCSS(lettermint.OnUnsupportedCSS(func(selectors []string) {
+//	        metrics.Add("css_not_inlined", len(selectors))
+//	    }))
+func OnUnsupportedCSS(fn func(selectors []string)) InlineCSSOption {
+	return func(o *inlineCSSOptions) {
+		o.onUnsupported = fn
+	}
+}
+
+// InlineCSS inlines the CSS of the HTML body into style attributes when the
+// email is built or sent. See InlineCSS.
+//
+// Selectors that cannot be inlined are passed to the function given with
+// OnUnsupportedCSS, and logged as a warning if the client has a logger.
+func (b *EmailBuilder) InlineCSS(opts ...InlineCSSOption) *EmailBuilder {
+	o := &inlineCSSOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	b.inlineCSS = o
+	return b
+}
+
+// inlineHTMLCSS inlines the CSS of an HTML body, reporting unsupported
+// selectors.
+func (b *EmailBuilder) inlineHTMLCSS(body string) string {
+	html, unsupported := InlineCSS(body)
+	if len(unsupported) == 0 {
+		return html
+	}
+	if b.client.logger != nil {
+		b.client.logger.LogAttrs(b.ctx, slog.LevelWarn, "lettermint css selectors not inlined",
+			slog.Any("selectors", unsupported),
+		)
+	}
+	if b.inlineCSS.onUnsupported != nil {
+		b.inlineCSS.onUnsupported(unsupported)
+	}
+	return html
+}
+
+// insertHeadStyle adds a <style> block with css to the document head,
+// creating the head if necessary.
+func insertHeadStyle(doc *htmlNode, css string) {
+	style := &htmlNode{Type: htmlElementNode, Tag: "style"}
+	style.appendChild(&htmlNode{Type: htmlTextNode, Data: css})
+
+	head := doc.find("head")
+	if head == nil {
+		if root := doc.find("html"); root != nil {
+			head = &htmlNode{Type: htmlElementNode, Tag: "head"}
+			root.prependChild(head)
+		}
+	}
+	if head == nil {
+		doc.prependChild(style)
+		return
+	}
+	head.appendChild(style)
+}
+
+// cssStyleSheet holds the rules parsed from the <style> blocks of a
+// document.
+type cssStyleSheet struct {
+	rules       []cssRule
+	kept        []string // CSS kept in the document head
+	unsupported []string // selectors that cannot be inlined
+}
+
+// cssRule is a style rule with a single selector.
+type cssRule struct {
+	selector     *cssSelector
+	declarations []cssDeclaration
+	order        int
+}
+
+type cssDeclaration struct {
+	property  string
+	value     string
+	important bool
+}
+
+// parse adds the rules of a stylesheet.
+func (s *cssStyleSheet) parse(css string) {
+	css = stripCSSComments(css)
+	for i := 0; i < len(css); {
+		for i < len(css) && isHTMLSpace(css[i]) {
+			i++
+		}
+		if i >= len(css) {
+			break
+		}
+
+		if css[i] == '@' {
+			end := scanCSS(css, i, ";{")
+			if end < len(css) && css[end] == '{' {
+				end = matchCSSBrace(css, end)
+			}
+			if rule := strings.TrimSpace(css[i:min(end+1, len(css))]); !strings.HasPrefix(strings.ToLower(rule), "@charset") {
+				s.kept = append(s.kept, rule)
+			}
+			i = end + 1
+			continue
+		}
+
+		open := scanCSS(css, i, "{")
+		if open >= len(css) {
+			break
+		}
+		end := matchCSSBrace(css, open)
+		prelude, body := css[i:open], css[open+1:min(end, len(css))]
+		i = end + 1
+
+		declarations := parseCSSDeclarations(body)
+		for _, text := range splitCSS(prelude, ',') {
+			text = strings.TrimSpace(text)
+			if text == "" {
+				continue
+			}
+			selector, err := parseCSSSelector(text)
+			if err != nil {
+				s.kept = append(s.kept, text+" { "+strings.TrimSpace(body)+" }")
+				s.reportUnsupported(text)
+				continue
+			}
+			s.rules = append(s.rules, cssRule{selector: selector, declarations: declarations, order: len(s.rules)})
+		}
+	}
+}
+
+func (s *cssStyleSheet) reportUnsupported(selector string) {
+	for _, existing := range s.unsupported {
+		if existing == selector {
+			return
+		}
+	}
+	s.unsupported = append(s.unsupported, selector)
+}
+
+// apply sets the style attributes of the elements of the document body.
+func (s *cssStyleSheet) apply(doc *htmlNode) {
+	// The memo is shared by all rules matched against an element and reset
+	// for the next one, so it stays small and is allocated once.
+	memo := map[cssMatch]bool{}
+	doc.walk(func(n *htmlNode) bool {
+		if n.Type == htmlDocumentNode {
+			return true
+		}
+		if n.Type != htmlElementNode || n.Tag == "head" {
+			return false
+		}
+		clear(memo)
+		if style := s.style(n, memo); style != "" {
+			n.setAttr("style", style)
+		}
+		return true
+	})
+}
+
+// style returns the style attribute of an element after applying the
+// matching rules.
+func (s *cssStyleSheet) style(n *htmlNode, memo map[cssMatch]bool) string {
+	type candidate struct {
+		cssDeclaration
+		inline      bool
+		specificity [3]int
+		order       int
+	}
+	var candidates []candidate
+	for _, rule := range s.rules {
+		if !rule.selector.match(n, memo) {
+			continue
+		}
+		for i, declaration := range rule.declarations {
+			candidates = append(candidates, candidate{declaration, false, rule.selector.specificity, rule.order<<16 + i})
+		}
+	}
+	if len(candidates) == 0 {
+		return ""
+	}
+	if inline, ok := n.attr("style"); ok {
+		for i, declaration := range parseCSSDeclarations(inline) {
+			candidates = append(candidates, candidate{declaration, true, [3]int{}, i})
+		}
+	}
+
+	// Later candidates win: important declarations over normal ones, then
+	// inline styles over rules, then higher specificity, then later rules.
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		switch {
+		case a.important != b.important:
+			return !a.important
+		case a.inline != b.inline:
+			return !a.inline
+		case a.specificity != b.specificity:
+			return lessSpecific(a.specificity, b.specificity)
+		default:
+			return a.order < b.order
+		}
+	})
+
+	var properties []string
+	values := map[string]cssDeclaration{}
+	for _, c := range candidates {
+		if _, ok := values[c.property]; !ok {
+			properties = append(properties, c.property)
+		}
+		values[c.property] = c.cssDeclaration
+	}
+
+	parts := make([]string, 0, len(properties))
+	for _, property := range properties {
+		declaration := values[property]
+		part := property + ": " + declaration.value
+		if declaration.important {
+			part += " !important"
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, "; ")
+}
+
+func lessSpecific(a, b [3]int) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return false
+}
+
+// parseCSSDeclarations parses the declarations of a rule or style
+// attribute, ignoring invalid ones.
+func parseCSSDeclarations(css string) []cssDeclaration {
+	var declarations []cssDeclaration
+	for _, text := range splitCSS(css, ';') {
+		colon := strings.IndexByte(text, ':')
+		if colon < 0 {
+			continue
+		}
+		declaration := cssDeclaration{
+			property: strings.ToLower(strings.TrimSpace(text[:colon])),
+			value:    strings.TrimSpace(text[colon+1:]),
+		}
+		if bang := strings.LastIndexByte(declaration.value, '!'); bang >= 0 && strings.EqualFold(strings.TrimSpace(declaration.value[bang+1:]), "important") {
+			declaration.value = strings.TrimSpace(declaration.value[:bang])
+			declaration.important = true
+		}
+		if declaration.property != "" && declaration.value != "" {
+			declarations = append(declarations, declaration)
+		}
+	}
+	return declarations
+}
+
+func stripCSSComments(css string) string {
+	var b strings.Builder
+	for {
+		start := strings.Index(css, "/*")
+		if start < 0 {
+			b.WriteString(css)
+			return b.String()
+		}
+		b.WriteString(css[:start])
+		end := strings.Index(css[start+2:], "*/")
+		if end < 0 {
+			return b.String()
+		}
+		css = css[start+2+end+2:]
+	}
+}
+
+// scanCSS returns the index of the first of the given characters at or
+// after i that is not quoted or nested in parentheses, or len(css).
+func scanCSS(css string, i int, chars string) int {
+	depth := 0
+	var quote byte
+	for ; i < len(css); i++ {
+		c := css[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '(' || c == '[':
+			depth++
+		case c == ')' || c == ']':
+			depth--
+		case depth <= 0 && strings.IndexByte(chars, c) >= 0:
+			return i
+		}
+	}
+	return len(css)
+}
+
+// matchCSSBrace returns the index of the brace closing the block opened at
+// i, or len(css).
+func matchCSSBrace(css string, i int) int {
+	depth := 0
+	for ; i < len(css); i++ {
+		i = scanCSS(css, i, "{}")
+		if i >= len(css) {
+			break
+		}
+		if css[i] == '{' {
+			depth++
+		} else if depth--; depth == 0 {
+			return i
+		}
+	}
+	return len(css)
+}
+
+// splitCSS splits css at sep, ignoring separators that are quoted or in
+// parentheses.
+func splitCSS(css string, sep byte) []string {
+	var parts []string
+	for {
+		end := scanCSS(css, 0, string(sep))
+		parts = append(parts, css[:end])
+		if end >= len(css) {
+			return parts
+		}
+		css = css[end+1:]
+	}
+}
+
+// cssSelector is a complex selector: compound selectors joined by
+// combinators.
+type cssSelector struct {
+	compounds   []cssCompound
+	combinators []byte // combinators[i] joins compounds[i] and compounds[i+1]
+	specificity [3]int
+}
+
+type cssCompound struct {
+	tag     string // empty or "*" for any element
+	id      string
+	classes []string
+	attrs   []cssAttrSelector
+	pseudos []string
+}
+
+type cssAttrSelector struct {
+	name, op, value string
+}
+
+// cssPseudoClasses are the supported pseudo-classes.
+var cssPseudoClasses = map[string]bool{
+	"first-child": true, "last-child": true, "only-child": true,
+}
+
+// parseCSSSelector parses a single selector. It returns an error for
+// selectors that cannot be inlined.
+func parseCSSSelector(text string) (*cssSelector, error) {
+	s := &cssSelector{}
+	combinator := byte(0)
+	for i := 0; i < len(text); {
+		c := text[i]
+		if isHTMLSpace(c) {
+			if combinator == 0 && len(s.compounds) > 0 {
+				combinator = ' '
+			}
+			i++
+			continue
+		}
+		if c == '>' || c == '+' || c == '~' {
+			if len(s.compounds) == 0 || combinator != 0 && combinator != ' ' {
+				return nil, fmt.Errorf("unexpected %q", c)
+			}
+			combinator = c
+			i++
+			continue
+		}
+
+		compound, n, err := parseCSSCompound(text[i:])
+		if err != nil {
+			return nil, err
+		}
+		if len(s.compounds) > 0 {
+			if combinator == 0 {
+				return nil, fmt.Errorf("unexpected %q", text[i:])
+			}
+			s.combinators = append(s.combinators, combinator)
+		}
+		combinator = 0
+		s.compounds = append(s.compounds, compound)
+		i += n
+
+		if compound.id != "" {
+			s.specificity[0]++
+		}
+		s.specificity[1] += len(compound.classes) + len(compound.attrs) + len(compound.pseudos)
+		if compound.tag != "" && compound.tag != "*" {
+			s.specificity[2]++
+		}
+	}
+	if len(s.compounds) == 0 || combinator != 0 && combinator != ' ' {
+		return nil, fmt.Errorf("incomplete selector")
+	}
+	return s, nil
+}
+
+// parseCSSCompound parses the compound selector at the start of text and
+// returns its length.
+func parseCSSCompound(text string) (cssCompound, int, error) {
+	var compound cssCompound
+	i := 0
+	if i < len(text) && text[i] == '*' {
+		compound.tag = "*"
+		i++
+	} else if name := scanCSSIdent(text); name != "" {
+		compound.tag = strings.ToLower(name)
+		i += len(name)
+	}
+
+	for i < len(text) {
+		switch text[i] {
+		case '.', '#':
+			name := scanCSSIdent(text[i+1:])
+			if name == "" {
+				return compound, 0, fmt.Errorf("unexpected %q", text[i:])
+			}
+			if text[i] == '.' {
+				compound.classes = append(compound.classes, name)
+			} else {
+				compound.id = name
+			}
+			i += 1 + len(name)
+		case '[':
+			end := strings.IndexByte(text[i:], ']')
+			if end < 0 {
+				return compound, 0, fmt.Errorf("unclosed attribute selector")
+			}
+			attr, err := parseCSSAttrSelector(text[i+1 : i+end])
+			if err != nil {
+				return compound, 0, err
+			}
+			compound.attrs = append(compound.attrs, attr)
+			i += end + 1
+		case ':':
+			name := scanCSSIdent(text[i+1:])
+			if !cssPseudoClasses[strings.ToLower(name)] {
+				return compound, 0, fmt.Errorf("unsupported pseudo-class in %q", text[i:])
+			}
+			compound.pseudos = append(compound.pseudos, strings.ToLower(name))
+			i += 1 + len(name)
+		default:
+			if i == 0 {
+				return compound, 0, fmt.Errorf("unexpected %q", text)
+			}
+			return compound, i, nil
+		}
+	}
+	return compound, i, nil
+}
+
+func parseCSSAttrSelector(text string) (cssAttrSelector, error) {
+	var attr cssAttrSelector
+	opIndex := strings.IndexAny(text, "=~|^$*")
+	if opIndex < 0 {
+		attr.name = strings.ToLower(strings.TrimSpace(text))
+	} else {
+		attr.name = strings.ToLower(strings.TrimSpace(text[:opIndex]))
+		rest := text[opIndex:]
+		if rest[0] == '=' {
+			attr.op = "="
+		} else if len(rest) > 1 && rest[1] == '=' {
+			attr.op = rest[:2]
+		} else {
+			return attr, fmt.Errorf("invalid attribute selector [%s]", text)
+		}
+		value := strings.TrimSpace(rest[len(attr.op):])
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		} else if strings.ContainsAny(value, " \t\"'") {
+			return attr, fmt.Errorf("unsupported attribute selector [%s]", text)
+		}
+		attr.value = value
+	}
+	if attr.name == "" || scanCSSIdent(attr.name) != attr.name {
+		return attr, fmt.Errorf("invalid attribute selector [%s]", text)
+	}
+	return attr, nil
+}
+
+// scanCSSIdent returns the identifier at the start of s.
+func scanCSSIdent(s string) string {
+	i := 0
+	for i < len(s) {
+		c := s[i]
+		if c == '-' || c == '_' || c >= '0' && c <= '9' || isASCIILetter(c) || c >= 0x80 {
+			i++
+			continue
+		}
+		break
+	}
+	return s[:i]
+}
+
+// match reports whether an element matches the selector. Results for the
+// compounds before the last one are memoized in memo.
+func (s *cssSelector) match(n *htmlNode, memo map[cssMatch]bool) bool {
+	if len(s.compounds) == 1 {
+		return s.compounds[0].match(n)
+	}
+	return s.matchCompound(len(s.compounds)-1, n, memo)
+}
+
+// cssMatch is a compound selector of a complex selector and an element.
+// Results are memoized, because the descendant and subsequent-sibling
+// combinators would otherwise try exponentially many paths through the
+// document.
+type cssMatch struct {
+	selector *cssSelector
+	compound int
+	node     *htmlNode
+}
+
+func (s *cssSelector) matchAt(i int, n *htmlNode, memo map[cssMatch]bool) bool {
+	key := cssMatch{s, i, n}
+	if matched, ok := memo[key]; ok {
+		return matched
+	}
+	matched := s.matchCompound(i, n, memo)
+	memo[key] = matched
+	return matched
+}
+
+func (s *cssSelector) matchCompound(i int, n *htmlNode, memo map[cssMatch]bool) bool {
+	if !s.compounds[i].match(n) {
+		return false
+	}
+	if i == 0 {
+		return true
+	}
+	switch s.combinators[i-1] {
+	case '>':
+		parent := parentElement(n)
+		return parent != nil && s.matchAt(i-1, parent, memo)
+	case '+':
+		previous := previousElement(n)
+		return previous != nil && s.matchAt(i-1, previous, memo)
+	case '~':
+		for previous := previousElement(n); previous != nil; previous = previousElement(previous) {
+			if s.matchAt(i-1, previous, memo) {
+				return true
+			}
+		}
+	default:
+		for parent := parentElement(n); parent != nil; parent = parentElement(parent) {
+			if s.matchAt(i-1, parent, memo) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (c *cssCompound) match(n *htmlNode) bool {
+	if c.tag != "" && c.tag != "*" && c.tag != n.Tag {
+		return false
+	}
+	if c.id != "" && attrValue(n, "id") != c.id {
+		return false
+	}
+	if len(c.classes) > 0 {
+		classList := attrValue(n, "class")
+		for _, class := range c.classes {
+			if !hasClass(classList, class) {
+				return false
+			}
+		}
+	}
+	for _, attr := range c.attrs {
+		if !attr.match(n) {
+			return false
+		}
+	}
+	for _, pseudo := range c.pseudos {
+		first, last := previousElement(n) == nil, nextElement(n) == nil
+		switch {
+		case pseudo == "first-child" && !first,
+			pseudo == "last-child" && !last,
+			pseudo == "only-child" && !(first && last):
+			return false
+		}
+	}
+	return true
+}
+
+// hasClass reports whether a whitespace-separated class list contains class,
+// without splitting the list.
+func hasClass(classList, class string) bool {
+	if !strings.Contains(classList, class) {
+		return false
+	}
+	for {
+		classList = strings.TrimLeft(classList, " \t\n\r\f")
+		if classList == "" {
+			return false
+		}
+		end := strings.IndexAny(classList, " \t\n\r\f")
+		if end < 0 {
+			end = len(classList)
+		}
+		if classList[:end] == class {
+			return true
+		}
+		classList = classList[end:]
+	}
+}
+
+func (a cssAttrSelector) match(n *htmlNode) bool {
+	value, ok := n.attr(a.name)
+	if !ok {
+		return false
+	}
+	switch a.op {
+	case "":
+		return true
+	case "=":
+		return value == a.value
+	case "~=":
+		return containsString(strings.Fields(value), a.value)
+	case "|=":
+		return value == a.value || strings.HasPrefix(value, a.value+"-")
+	case "^=":
+		return a.value != "" && strings.HasPrefix(value, a.value)
+	case "$=":
+		return a.value != "" && strings.HasSuffix(value, a.value)
+	case "*=":
+		return a.value != "" && strings.Contains(value, a.value)
+	}
+	return false
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func parentElement(n *htmlNode) *htmlNode {
+	if n.Parent != nil && n.Parent.Type == htmlElementNode {
+		return n.Parent
+	}
+	return nil
+}
+
+// previousElement returns the previous sibling element of n.
+func previousElement(n *htmlNode) *htmlNode {
+	if n.Parent == nil {
+		return nil
+	}
+	var previous *htmlNode
+	for _, sibling := range n.Parent.Children {
+		if sibling == n {
+			return previous
+		}
+		if sibling.Type == htmlElementNode {
+			previous = sibling
+		}
+	}
+	return nil
+}
+
+// nextElement returns the next sibling element of n.
+func nextElement(n *htmlNode) *htmlNode {
+	if n.Parent == nil {
+		return nil
+	}
+	found := false
+	for _, sibling := range n.Parent.Children {
+		if sibling == n {
+			found = true
+		} else if found && sibling.Type == htmlElementNode {
+			return sibling
+		}
+	}
+	return nil
+}
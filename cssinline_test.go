@@ -0,0 +1,183 @@
+package lettermint
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestInlineCSS(t *testing.T) {
+	tests := []struct {
+		name            string
+		html            string
+		want            string
+		wantUnsupported []string
+	}{
+		{
+			name: "type, class and id selectors",
+			html: `<style>p { color: #333; margin: 0 } .lead { font-size: 18px } #intro { color: #000 }</style>` +
+				`<p id="intro" class="lead">Hi</p><p>There</p>`,
+			want: `<p id="intro" class="lead" style="color: #000; margin: 0; font-size: 18px">Hi</p><p style="color: #333; margin: 0">There</p>`,
+		},
+		{
+			name: "XML declaration",
+			html: `<?xml version="1.0" encoding="utf-8"?><!DOCTYPE html><style>p { margin: 0 }</style><p>Hi</p>`,
+			want: `<?xml version="1.0" encoding="utf-8"?><!DOCTYPE html><p style="margin: 0">Hi</p>`,
+		},
+		{
+			name: "cascade",
+			html: `<style>a { color: red !important; text-decoration: none } a.button { color: blue; text-decoration: underline }</style>` +
+				`<a class="button" style="text-decoration: overline; color: green">Go</a>`,
+			want: `<a class="button" style="text-decoration: overline; color: red !important">Go</a>`,
+		},
+		{
+			name: "combinators and attributes",
+			html: `<style>table > tr > td { padding: 4px } div p { margin: 0 } h1 + p { color: gray } h1 ~ ul { color: navy }` +
+				` a[href^="https:"] { color: green } td[align=right] { text-align: right }</style>` +
+				`<div><section><p>Nested</p></section></div><h1>T</h1><p>Lead</p><p>Body</p><ul></ul>` +
+				`<table><tr><td align="right">1</td></tr></table><a href="https://example.com">Link</a><a href="/x">Relative</a>`,
+			want: `<div><section><p style="margin: 0">Nested</p></section></div><h1>T</h1><p style="color: gray">Lead</p><p>Body</p><ul style="color: navy"></ul>` +
+				`<table><tr><td align="right" style="padding: 4px; text-align: right">1</td></tr></table><a href="https://example.com" style="color: green">Link</a><a href="/x">Relative</a>`,
+		},
+		{
+			name: "structural pseudo-classes",
+			html: `<style>li:first-child { font-weight: bold } li:last-child { color: gray } b:only-child { color: red }</style>` +
+				`<ul><li><b>One</b></li><li>Two</li><li>Three</li></ul>`,
+			want: `<ul><li style="font-weight: bold"><b style="color: red">One</b></li><li>Two</li><li style="color: gray">Three</li></ul>`,
+		},
+		{
+			name: "media queries and unsupported selectors",
+			html: `<html><head><title>T</title><style>/* base */ a { color: blue } a:hover, a::after { color: red }` +
+				` @media (max-width: 600px) { .wide { width: 100% !important } } @charset "utf-8";</style></head>` +
+				`<body><a class="wide">Go</a></body></html>`,
+			want: `<html><head><title>T</title><style>a:hover { color: red }` + "\n" + `a::after { color: red }` + "\n" +
+				`@media (max-width: 600px) { .wide { width: 100% !important } }</style></head><body><a class="wide" style="color: blue">Go</a></body></html>`,
+			wantUnsupported: []string{"a:hover", "a::after"},
+		},
+		{
+			name: "fragment with kept rules",
+			html: `<style>@media screen and (max-width: 600px) { p { margin: 0 } } p { margin: 8px }</style><p>Hi</p>`,
+			want: `<style>@media screen and (max-width: 600px) { p { margin: 0 } }</style><p style="margin: 8px">Hi</p>`,
+		},
+		{
+			name: "print styles are left alone",
+			html: `<head><style media="print">p { display: none }</style></head><p>Hi &amp; bye</p>`,
+			want: `<head><style media="print">p { display: none }</style></head><p>Hi &amp; bye</p>`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, unsupported := InlineCSS(tt.html)
+			if got != tt.want {
+				t.Errorf("InlineCSS() =\n%s\nwant\n%s", got, tt.want)
+			}
+			if !reflect.DeepEqual(unsupported, tt.wantUnsupported) {
+				t.Errorf("unsupported = %q, want %q", unsupported, tt.wantUnsupported)
+			}
+		})
+	}
+}
+
+func TestEmailBuilder_InlineCSS(t *testing.T) {
+	var got emailPayload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		_, _ = w.Write([]byte(`{"message_id":"msg_1","status":"pending"}`))
+	}))
+	defer server.Close()
+
+	var logs bytes.Buffer
+	client, _ := New("test-token", WithBaseURL(server.URL),
+		WithLogger(slog.New(slog.NewTextHandler(&logs, nil))),
+	)
+	_, err := client.Email(context.Background()).
+		From("sender@example.com").
+		To("recipient@example.com").
+		Subject("Hello").
+		HTML(`<style>p { color: red } p:hover { color: blue }</style><p>Hi</p>`).
+		InlineCSS().
+		TextFromHTML().
+		Send()
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	if want := `<style>p:hover { color: blue }</style><p style="color: red">Hi</p>`; got.HTML != want {
+		t.Errorf("HTML = %s, want %s", got.HTML, want)
+	}
+	if got.Text != "Hi" {
+		t.Errorf("Text = %q, want text derived from the inlined HTML", got.Text)
+	}
+	if !strings.Contains(logs.String(), "lettermint css selectors not inlined") || !strings.Contains(logs.String(), "p:hover") {
+		t.Errorf("logs = %s, want a warning about p:hover", logs.String())
+	}
+}
+
+func TestEmailBuilder_InlineCSS_OnUnsupported(t *testing.T) {
+	client, _ := New("test-token")
+	email := func() *EmailBuilder {
+		return client.Email(context.Background()).
+			From("sender@example.com").
+			To("recipient@example.com").
+			Subject("Hello")
+	}
+
+	var reported []string
+	msg, err := email().
+		HTML(`<style>p { color: red } p:hover, a::after { color: blue }</style><p>Hi</p>`).
+		InlineCSS(OnUnsupportedCSS(func(selectors []string) { reported = selectors })).
+		Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	if !reflect.DeepEqual(reported, []string{"p:hover", "a::after"}) {
+		t.Errorf("reported = %q, want the selectors that were not inlined", reported)
+	}
+	if !strings.Contains(msg.HTML, `<p style="color: red">`) {
+		t.Errorf("HTML = %s, want the supported rule inlined", msg.HTML)
+	}
+
+	called := false
+	if _, err := email().
+		HTML(`<style>p { color: red }</style><p>Hi</p>`).
+		InlineCSS(OnUnsupportedCSS(func([]string) { called = true })).
+		Build(); err != nil || called {
+		t.Errorf("Build() error = %v, called = %v, want no report when every rule is inlined", err, called)
+	}
+}
+
+func TestInlineCSS_ManyRules(t *testing.T) {
+	// Matching every rule against every element must not allocate per pair.
+	const n = 100
+	var css, body strings.Builder
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&css, "div .c%d { color: red }\n", i)
+		fmt.Fprintf(&body, `<p class="c%d">x</p>`, i)
+	}
+	html := "<style>" + css.String() + "</style><div>" + body.String() + "</div>"
+	got, _ := InlineCSS(html)
+	if strings.Count(got, `style="color: red"`) != n {
+		t.Fatalf("InlineCSS() = %s, want every paragraph styled", got)
+	}
+	if allocs := testing.AllocsPerRun(5, func() { InlineCSS(html) }); allocs > n*n/2 {
+		t.Errorf("InlineCSS() made %.0f allocations for %d rules and elements", allocs, n)
+	}
+}
+
+func TestInlineCSS_DeepDocument(t *testing.T) {
+	// Without memoization, matching a descendant selector that fails tries
+	// every combination of ancestors.
+	html := "<style>span " + strings.Repeat("div ", 30) + "p { color: red }</style>" +
+		strings.Repeat("<div>", 60) + "<p>Hi</p>" + strings.Repeat("</div>", 60)
+	got, _ := InlineCSS(html)
+	if strings.Contains(got, "style=") {
+		t.Errorf("InlineCSS() styled an element without a span ancestor")
+	}
+}
@@ -37,6 +37,20 @@
 //	    IdempotencyKey("report-dec-2024").
 //	    Send()
 //
+// To send an email later or from another goroutine, build it as a Message
+// and send it with Client.Send:
+//
+//	msg, err := client.Email(ctx).
+//	    From("sender@example.com").
+//	    To("recipient@example.com").
+//	    Subject("Hello").
+//	    HTML("<p>Hello World</p>").
+//	    Build()
+//	if err != nil {
+//	    return err
+//	}
+//	resp, err := client.Send(workerCtx, msg)
+//
 // # Client Configuration
 //
 // Use functional options to customize the client:
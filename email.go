@@ -1,25 +1,26 @@
 package lettermint
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
-	"runtime"
-	"strings"
+	"time"
 )
 
 // EmailBuilder provides a fluent interface for composing and sending emails.
 //
 // Create a new EmailBuilder using Client.Email(ctx).
-// The builder is NOT safe for concurrent use; create a new builder for each email.
+// The builder is NOT safe for concurrent use; create a new builder for each email,
+// or use Build to get a Message that can be sent independently.
 type EmailBuilder struct {
 	client         *Client
 	ctx            context.Context
 	payload        *emailPayload
 	idempotencyKey string
+	textFromHTML   bool
+	inlineCSS      *inlineCSSOptions // nil unless InlineCSS was called
+	err            error
 }
 
 // From sets the sender email address.
@@ -62,6 +63,55 @@ func (b *EmailBuilder) ReplyTo(emails ...string) *EmailBuilder {
 	return b
 }
 
+// FromAddress sets the sender address.
+//
+// The address is validated immediately; an invalid address is returned by
+// Send as an *AddressError.
+func (b *EmailBuilder) FromAddress(address Address) *EmailBuilder {
+	from, err := address.format()
+	if err != nil {
+		b.recordError(&AddressError{Field: "from", Address: address, Err: err})
+		return b
+	}
+	b.payload.From = from
+	return b
+}
+
+// ToAddress adds one or more recipient addresses. See FromAddress.
+func (b *EmailBuilder) ToAddress(addresses ...Address) *EmailBuilder {
+	b.payload.To = b.appendAddresses("to", b.payload.To, addresses)
+	return b
+}
+
+// CCAddress adds one or more CC recipient addresses. See FromAddress.
+func (b *EmailBuilder) CCAddress(addresses ...Address) *EmailBuilder {
+	b.payload.CC = b.appendAddresses("cc", b.payload.CC, addresses)
+	return b
+}
+
+// BCCAddress adds one or more BCC recipient addresses. See FromAddress.
+func (b *EmailBuilder) BCCAddress(addresses ...Address) *EmailBuilder {
+	b.payload.BCC = b.appendAddresses("bcc", b.payload.BCC, addresses)
+	return b
+}
+
+// ReplyToAddress adds one or more Reply-To addresses. See FromAddress.
+func (b *EmailBuilder) ReplyToAddress(addresses ...Address) *EmailBuilder {
+	b.payload.ReplyTo = b.appendAddresses("reply_to", b.payload.ReplyTo, addresses)
+	return b
+}
+
+// appendAddresses validates addresses and appends them to list, recording
+// the first invalid address.
+func (b *EmailBuilder) appendAddresses(field string, list []string, addresses []Address) []string {
+	formatted, err := formatAddresses(field, len(list), addresses)
+	if err != nil {
+		b.recordError(err)
+		return list
+	}
+	return append(list, formatted...)
+}
+
 // Subject sets the email subject line.
 func (b *EmailBuilder) Subject(subject string) *EmailBuilder {
 	b.payload.Subject = subject
@@ -110,7 +160,8 @@ func (b *EmailBuilder) Headers(headers map[string]string) *EmailBuilder {
 
 // Attach adds a file attachment to the email.
 //
-// The content must be base64-encoded.
+// The content must be base64-encoded. Use AttachReader, AttachFile or
+// AttachFS to attach unencoded content.
 func (b *EmailBuilder) Attach(filename, content string) *EmailBuilder {
 	return b.AttachWithContentID(filename, content, "")
 }
@@ -173,6 +224,8 @@ func (b *EmailBuilder) Route(route string) *EmailBuilder {
 //
 // If you provide the same idempotency key for multiple requests,
 // only the first one will be processed. Use this when retrying failed requests.
+//
+// Overrides the key generated by WithIdempotencyKeys or WithIdempotencyKeyFunc.
 func (b *EmailBuilder) IdempotencyKey(key string) *EmailBuilder {
 	b.idempotencyKey = key
 	return b
@@ -185,59 +238,26 @@ func (b *EmailBuilder) IdempotencyKey(key string) *EmailBuilder {
 //
 // The context passed to Email() controls the request lifecycle.
 // Use context.WithTimeout() or context.WithDeadline() for custom timeouts.
+//
+// Send is Build followed by Client.Send. If Build fails, Send makes no
+// request and leaves the builder as it is, including an error recorded by a
+// builder method such as AttachFile; start over with Client.Email. Otherwise
+// the builder is reset after the request, whether it succeeded or not, so it
+// can compose the next email. Use Build to send the same email again or from
+// another goroutine.
 func (b *EmailBuilder) Send() (*SendResponse, error) {
-	if err := b.validate(); err != nil {
-		return nil, fmt.Errorf("%w: %v", ErrInvalidRequest, err)
-	}
-	defer b.reset()
-
-	jsonData, err := json.Marshal(b.payload)
+	msg, err := b.Build()
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal email payload: %w", err)
-	}
-
-	url := fmt.Sprintf("%s/send", strings.TrimSuffix(b.client.baseURL, "/"))
-	req, err := http.NewRequestWithContext(b.ctx, http.MethodPost, url, bytes.NewReader(jsonData))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Accept", "application/json")
-	req.Header.Set("x-lettermint-token", b.client.apiToken)
-	req.Header.Set("User-Agent", fmt.Sprintf("Lettermint/%s (Go; %s)", Version, runtime.Version()))
-
-	if b.idempotencyKey != "" {
-		req.Header.Set("Idempotency-Key", b.idempotencyKey)
-	}
-
-	resp, err := b.client.httpClient.Do(req)
-	if err != nil {
-		if b.ctx.Err() == context.DeadlineExceeded {
-			return nil, fmt.Errorf("%w: %v", ErrTimeout, err)
-		}
-		if b.ctx.Err() == context.Canceled {
-			return nil, fmt.Errorf("request canceled: %w", err)
-		}
-		return nil, fmt.Errorf("request failed: %w", err)
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
-	}
-
-	if resp.StatusCode >= 400 {
-		return nil, parseAPIError(resp.StatusCode, body)
+		return nil, err
 	}
+	defer b.reset()
 
-	var sendResp SendResponse
-	if err := json.Unmarshal(body, &sendResp); err != nil {
-		return nil, fmt.Errorf("failed to parse response: %w", err)
+	// Build resolved the idempotency key; it is not generated again.
+	header := http.Header{}
+	if msg.IdempotencyKey != "" {
+		header.Set("Idempotency-Key", msg.IdempotencyKey)
 	}
-
-	return &sendResp, nil
+	return b.client.sendPayload(b.ctx, msg.payload(), header)
 }
 
 func (b *EmailBuilder) reset() {
@@ -248,30 +268,49 @@ func (b *EmailBuilder) reset() {
 		ReplyTo: []string{},
 	}
 	b.idempotencyKey = ""
+	b.textFromHTML = false
+	b.inlineCSS = nil
+	b.err = nil
 }
 
-// validate checks that all required fields are set.
-func (b *EmailBuilder) validate() error {
-	if b.payload.From == "" {
-		return fmt.Errorf("from address is required")
-	}
-	if len(b.payload.To) == 0 {
-		return fmt.Errorf("at least one recipient is required")
+// recordError records the first error of a builder method, which is
+// returned by Send.
+func (b *EmailBuilder) recordError(err error) {
+	if b.err == nil {
+		b.err = err
 	}
-	if b.payload.Subject == "" {
-		return fmt.Errorf("subject is required")
+}
+
+// body returns the request body of the payload. Payloads with attachments
+// read from a source are streamed.
+func (p *emailPayload) body() (bodySource, error) {
+	for _, attachment := range p.Attachments {
+		if attachment.streamed() {
+			return &emailBody{payload: p}, nil
+		}
 	}
-	if b.payload.HTML == "" && b.payload.Text == "" {
-		return fmt.Errorf("either html or text body is required")
+	data, err := json.Marshal(p)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal email payload: %w", err)
 	}
-	return nil
+	return bytesBody(data), nil
+}
+
+// validate checks that all required fields are set.
+func (b *EmailBuilder) validate() error {
+	msg := b.payload.message()
+	return msg.validate()
 }
 
 // parseAPIError converts an HTTP error response to an APIError.
-func parseAPIError(statusCode int, body []byte) error {
+func parseAPIError(statusCode int, header http.Header, body []byte) *APIError {
 	apiErr := &APIError{
 		StatusCode:   statusCode,
 		ResponseBody: string(body),
+		RequestID:    header.Get(HeaderRequestID),
+	}
+	if wait, ok := retryAfter(statusCode, header, time.Now()); ok {
+		apiErr.RetryAfter = wait
 	}
 
 	var errResp apiErrorResponse
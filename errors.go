@@ -3,6 +3,10 @@ package lettermint
 import (
 	"errors"
 	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 )
 
 // Sentinel errors for type checking with errors.Is()
@@ -10,12 +14,32 @@ var (
 	// ErrInvalidAPIToken indicates the API token is missing or invalid.
 	ErrInvalidAPIToken = errors.New("lettermint: invalid or missing API token")
 
+	// ErrInvalidConfig indicates the client configuration read from the
+	// environment or a config file is invalid.
+	ErrInvalidConfig = errors.New("lettermint: invalid configuration")
+
 	// ErrInvalidRequest indicates request validation failed before sending.
 	ErrInvalidRequest = errors.New("lettermint: invalid request")
 
+	// ErrBlockedFileType indicates an attachment has a file type that
+	// Lettermint does not accept. See WithAttachmentPolicy.
+	ErrBlockedFileType = errors.New("lettermint: blocked file type")
+
 	// ErrUnauthorized indicates authentication failed (HTTP 401).
 	ErrUnauthorized = errors.New("lettermint: unauthorized")
 
+	// ErrForbidden indicates the token lacks permission for the request (HTTP 403).
+	ErrForbidden = errors.New("lettermint: forbidden")
+
+	// ErrNotFound indicates the requested resource does not exist (HTTP 404).
+	ErrNotFound = errors.New("lettermint: not found")
+
+	// ErrConflict indicates the request conflicts with the current state of a resource (HTTP 409).
+	ErrConflict = errors.New("lettermint: conflict")
+
+	// ErrPayloadTooLarge indicates the request body exceeds the API limit (HTTP 413).
+	ErrPayloadTooLarge = errors.New("lettermint: payload too large")
+
 	// ErrValidation indicates validation error from API (HTTP 422).
 	ErrValidation = errors.New("lettermint: validation error")
 
@@ -28,6 +52,18 @@ var (
 	// ErrTimeout indicates request timeout.
 	ErrTimeout = errors.New("lettermint: request timeout")
 
+	// ErrNetwork indicates the request could not be sent or its response could
+	// not be read, e.g. because of a DNS failure or a dropped connection.
+	ErrNetwork = errors.New("lettermint: network error")
+
+	// ErrResponseTooLarge indicates a response body exceeded the configured
+	// size limit.
+	ErrResponseTooLarge = errors.New("lettermint: response body too large")
+
+	// ErrCircuitOpen indicates the request was not sent because the client's
+	// circuit breaker is open after repeated failures.
+	ErrCircuitOpen = errors.New("lettermint: circuit breaker open")
+
 	// ErrInvalidWebhookSignature indicates webhook signature verification failed.
 	ErrInvalidWebhookSignature = errors.New("lettermint: invalid webhook signature")
 
@@ -51,6 +87,14 @@ type APIError struct {
 
 	// ResponseBody is the raw response body for debugging.
 	ResponseBody string
+
+	// RequestID is the server's request ID. Include it in support requests.
+	RequestID string
+
+	// RetryAfter is the delay requested by the server through the Retry-After
+	// header or, when the request was rate limited, the rate limit reset
+	// header, or zero if none was given.
+	RetryAfter time.Duration
 }
 
 // Error implements the error interface.
@@ -68,6 +112,14 @@ func (e *APIError) Unwrap() error {
 		return ErrInvalidRequest
 	case 401:
 		return ErrUnauthorized
+	case 403:
+		return ErrForbidden
+	case 404:
+		return ErrNotFound
+	case 409:
+		return ErrConflict
+	case 413:
+		return ErrPayloadTooLarge
 	case 422:
 		return ErrValidation
 	case 429:
@@ -79,3 +131,129 @@ func (e *APIError) Unwrap() error {
 		return nil
 	}
 }
+
+// As allows errors.As to extract a *ValidationError from an HTTP 422 response.
+func (e *APIError) As(target interface{}) bool {
+	if v, ok := target.(**ValidationError); ok && e.StatusCode == 422 {
+		*v = &ValidationError{Fields: e.Errors, apiErr: e}
+		return true
+	}
+	return false
+}
+
+// ValidationError describes the field-level errors of a validation failure
+// (HTTP 422).
+//
+// Use errors.As to obtain it from an error returned by the SDK:
+//
+//	var validationErr *lettermint.ValidationError
+//	if errors.As(err, &validationErr) {
+//	    for _, fieldErr := range validationErr.FieldErrors() {
+//	        fmt.Printf("%s: %v\n", fieldErr.Field, fieldErr.Messages)
+//	    }
+//	}
+//
+// A ValidationError unwraps to the underlying *APIError, so errors.Is(err,
+// ErrValidation) keeps working.
+type ValidationError struct {
+	// Fields maps the field paths reported by the API, such as "subject"
+	// or "to.1", to their error messages.
+	Fields map[string][]string
+
+	apiErr *APIError
+}
+
+// FieldError is the validation error of a single field.
+type FieldError struct {
+	// Field is the full field path as reported by the API,
+	// e.g. "subject", "to.1" or "attachments.0.filename".
+	Field string
+
+	// Name is the top-level field name, e.g. "to" for "to.1".
+	Name string
+
+	// Index is the position in the Name list for indexed fields,
+	// e.g. 1 for "to.1", or -1 for fields that are not indexed.
+	Index int
+
+	// Messages contains the error messages for the field.
+	Messages []string
+}
+
+// Error implements the error interface.
+func (e *ValidationError) Error() string {
+	msg := "lettermint: validation error"
+	if e.apiErr != nil {
+		msg = e.apiErr.Error()
+	}
+	fieldErrs := e.FieldErrors()
+	if len(fieldErrs) == 0 {
+		return msg
+	}
+	parts := make([]string, 0, len(fieldErrs))
+	for _, fieldErr := range fieldErrs {
+		parts = append(parts, fieldErr.Field+": "+strings.Join(fieldErr.Messages, ", "))
+	}
+	return msg + ": " + strings.Join(parts, "; ")
+}
+
+// Unwrap returns the underlying *APIError.
+func (e *ValidationError) Unwrap() error {
+	if e.apiErr == nil {
+		return ErrValidation
+	}
+	return e.apiErr
+}
+
+// Field returns the error messages for the exact field path, e.g. "to.1".
+func (e *ValidationError) Field(field string) []string {
+	return e.Fields[field]
+}
+
+// Has reports whether the field, or any of its indexed or nested fields,
+// has errors. Has("to") is true for errors on "to" and on "to.1".
+func (e *ValidationError) Has(name string) bool {
+	return len(e.ForField(name)) > 0
+}
+
+// ForField returns the errors of the field and all of its indexed or nested
+// fields, sorted by field path. ForField("to") includes "to" and "to.1".
+func (e *ValidationError) ForField(name string) []FieldError {
+	var out []FieldError
+	for _, fieldErr := range e.FieldErrors() {
+		if fieldErr.Field == name || strings.HasPrefix(fieldErr.Field, name+".") {
+			out = append(out, fieldErr)
+		}
+	}
+	return out
+}
+
+// FieldErrors returns all field errors sorted by field path.
+func (e *ValidationError) FieldErrors() []FieldError {
+	out := make([]FieldError, 0, len(e.Fields))
+	for field, messages := range e.Fields {
+		out = append(out, newFieldError(field, messages))
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Name != out[j].Name {
+			return out[i].Name < out[j].Name
+		}
+		if out[i].Index != out[j].Index {
+			return out[i].Index < out[j].Index
+		}
+		return out[i].Field < out[j].Field
+	})
+	return out
+}
+
+func newFieldError(field string, messages []string) FieldError {
+	fieldErr := FieldError{Field: field, Name: field, Index: -1, Messages: messages}
+	parts := strings.SplitN(field, ".", 3)
+	if len(parts) > 1 {
+		fieldErr.Name = parts[0]
+		if index, err := strconv.Atoi(parts[1]); err == nil {
+			fieldErr.Index = index
+		}
+	}
+	return fieldErr
+}
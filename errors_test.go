@@ -0,0 +1,126 @@
+package lettermint
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAPIError_Unwrap(t *testing.T) {
+	tests := []struct {
+		statusCode int
+		want       error
+	}{
+		{statusCode: 400, want: ErrInvalidRequest},
+		{statusCode: 401, want: ErrUnauthorized},
+		{statusCode: 403, want: ErrForbidden},
+		{statusCode: 404, want: ErrNotFound},
+		{statusCode: 409, want: ErrConflict},
+		{statusCode: 413, want: ErrPayloadTooLarge},
+		{statusCode: 422, want: ErrValidation},
+		{statusCode: 429, want: ErrRateLimited},
+		{statusCode: 500, want: ErrServerError},
+		{statusCode: 503, want: ErrServerError},
+		{statusCode: 418, want: nil},
+	}
+
+	for _, tt := range tests {
+		err := &APIError{StatusCode: tt.statusCode}
+		if got := err.Unwrap(); got != tt.want {
+			t.Errorf("APIError{%d}.Unwrap() = %v, want %v", tt.statusCode, got, tt.want)
+		}
+	}
+}
+
+func TestAPIError_NotFoundFromService(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"message":"Domain not found"}`))
+	}))
+	defer server.Close()
+
+	api, _ := NewAPI("api-token", WithBaseURL(server.URL))
+	_, err := api.Domains.Retrieve(context.Background(), "missing")
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Domains.Retrieve() error = %v, want ErrNotFound", err)
+	}
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) {
+		t.Fatal("errors.As(*ValidationError) = true for a 404 response")
+	}
+}
+
+func TestValidationError_FromSend(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"message": "The given data was invalid.",
+			"errors": map[string][]string{
+				"subject":                {"The subject field is required."},
+				"to.1":                   {"The to.1 field must be a valid email address."},
+				"to.0":                   {"The to.0 field must be a valid email address."},
+				"attachments.0.filename": {"The filename is not allowed."},
+			},
+		})
+	}))
+	defer server.Close()
+
+	client, _ := New("test-token", WithBaseURL(server.URL))
+	_, err := client.Email(context.Background()).
+		From("sender@example.com").
+		To("a", "b").
+		Subject("Test").
+		Text("Body").
+		Send()
+
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("errors.As(*ValidationError) = false for %v", err)
+	}
+	if !errors.Is(validationErr, ErrValidation) {
+		t.Fatal("ValidationError does not unwrap to ErrValidation")
+	}
+	var apiErr *APIError
+	if !errors.As(validationErr, &apiErr) || apiErr.StatusCode != 422 {
+		t.Fatal("ValidationError does not unwrap to *APIError")
+	}
+
+	if got := validationErr.Field("to.1"); len(got) != 1 || !strings.Contains(got[0], "to.1") {
+		t.Fatalf("Field(to.1) = %#v", got)
+	}
+	if !validationErr.Has("to") || !validationErr.Has("attachments") || validationErr.Has("from") {
+		t.Fatal("Has() reports wrong fields")
+	}
+
+	var indexes []int
+	for _, fieldErr := range validationErr.ForField("to") {
+		if fieldErr.Name != "to" {
+			t.Fatalf("ForField(to) returned %q", fieldErr.Field)
+		}
+		indexes = append(indexes, fieldErr.Index)
+	}
+	if !reflect.DeepEqual(indexes, []int{0, 1}) {
+		t.Fatalf("ForField(to) indexes = %v, want [0 1]", indexes)
+	}
+
+	var fields []string
+	for _, fieldErr := range validationErr.FieldErrors() {
+		fields = append(fields, fieldErr.Field)
+	}
+	want := []string{"attachments.0.filename", "subject", "to.0", "to.1"}
+	if !reflect.DeepEqual(fields, want) {
+		t.Fatalf("FieldErrors() = %v, want %v", fields, want)
+	}
+
+	if subject := validationErr.ForField("subject"); len(subject) != 1 || subject[0].Index != -1 {
+		t.Fatalf("ForField(subject) = %#v", subject)
+	}
+	if !strings.Contains(validationErr.Error(), "subject: The subject field is required.") {
+		t.Fatalf("Error() = %q", validationErr.Error())
+	}
+}
@@ -0,0 +1,390 @@
+package lettermint
+
+import (
+	"html"
+	"strings"
+)
+
+// This file implements a small, forgiving HTML parser and serializer for
+// email bodies. It is not a complete HTML5 parser: it builds a tree from the
+// tags as written, closes void and implicitly closed elements, and ignores
+// stray end tags. That is enough for the HTML emails are written in, without
+// depending on golang.org/x/net/html.
+
+type htmlNodeType int
+
+const (
+	htmlDocumentNode htmlNodeType = iota
+	htmlElementNode
+	htmlTextNode
+	htmlCommentNode
+	htmlDoctypeNode
+	htmlProcessingInstructionNode // <?xml ...?>, kept as written
+)
+
+type htmlAttr struct {
+	Key, Value string
+}
+
+// htmlNode is a node of a parsed HTML document.
+type htmlNode struct {
+	Type     htmlNodeType
+	Tag      string // lowercase tag name of elements
+	Attrs    []htmlAttr
+	Data     string // unescaped text, comment, doctype or processing instruction
+	Parent   *htmlNode
+	Children []*htmlNode
+}
+
+// attr returns the value of an attribute and whether it is set.
+func (n *htmlNode) attr(key string) (string, bool) {
+	for _, attr := range n.Attrs {
+		if attr.Key == key {
+			return attr.Value, true
+		}
+	}
+	return "", false
+}
+
+// setAttr sets the value of an attribute, adding it if necessary.
+func (n *htmlNode) setAttr(key, value string) {
+	for i, attr := range n.Attrs {
+		if attr.Key == key {
+			n.Attrs[i].Value = value
+			return
+		}
+	}
+	n.Attrs = append(n.Attrs, htmlAttr{Key: key, Value: value})
+}
+
+func (n *htmlNode) appendChild(child *htmlNode) {
+	child.Parent = n
+	n.Children = append(n.Children, child)
+}
+
+// prependChild inserts a node before the first child.
+func (n *htmlNode) prependChild(child *htmlNode) {
+	child.Parent = n
+	n.Children = append([]*htmlNode{child}, n.Children...)
+}
+
+// removeChild removes a child node.
+func (n *htmlNode) removeChild(child *htmlNode) {
+	for i, c := range n.Children {
+		if c == child {
+			n.Children = append(n.Children[:i], n.Children[i+1:]...)
+			child.Parent = nil
+			return
+		}
+	}
+}
+
+// walk calls fn for n and all its descendants in document order. If fn
+// returns false, the children of the node are skipped.
+func (n *htmlNode) walk(fn func(*htmlNode) bool) {
+	if !fn(n) {
+		return
+	}
+	for _, child := range n.Children {
+		child.walk(fn)
+	}
+}
+
+// find returns the first element with the given tag in document order.
+func (n *htmlNode) find(tag string) *htmlNode {
+	var found *htmlNode
+	n.walk(func(node *htmlNode) bool {
+		if found == nil && node.Type == htmlElementNode && node.Tag == tag {
+			found = node
+		}
+		return found == nil
+	})
+	return found
+}
+
+// htmlVoidElements have no content and no end tag.
+var htmlVoidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true,
+	"hr": true, "img": true, "input": true, "link": true, "meta": true,
+	"source": true, "track": true, "wbr": true,
+}
+
+// htmlRawTextElements contain text that is not parsed for tags.
+var htmlRawTextElements = map[string]bool{
+	"script": true, "style": true, "textarea": true, "title": true,
+}
+
+// htmlImpliedEnd lists the open elements that a start tag closes, e.g. a
+// <li> closes the previous <li> of the same list.
+var htmlImpliedEnd = map[string][]string{
+	"li":     {"li"},
+	"dt":     {"dt", "dd"},
+	"dd":     {"dt", "dd"},
+	"tr":     {"tr", "td", "th"},
+	"td":     {"td", "th"},
+	"th":     {"td", "th"},
+	"tbody":  {"thead", "tbody", "tr", "td", "th"},
+	"tfoot":  {"thead", "tbody", "tr", "td", "th"},
+	"option": {"option"},
+	"p":      {"p"},
+}
+
+// htmlScopeElements stop the search for an element to close implicitly, so
+// that a <li> in a nested list does not close the outer <li>.
+var htmlScopeElements = map[string]bool{
+	"ul": true, "ol": true, "dl": true, "table": true, "td": true, "th": true,
+	"div": true, "blockquote": true, "body": true, "html": true,
+}
+
+// parseHTML parses an HTML document or fragment.
+func parseHTML(s string) *htmlNode {
+	doc := &htmlNode{Type: htmlDocumentNode}
+	current := doc
+
+	// closeTo closes all elements up to and including target.
+	closeTo := func(target *htmlNode) {
+		current = target.Parent
+	}
+	// open finds the innermost open element with the tag.
+	open := func(tag string) *htmlNode {
+		for n := current; n != nil && n.Type == htmlElementNode; n = n.Parent {
+			if n.Tag == tag {
+				return n
+			}
+		}
+		return nil
+	}
+	// implied finds the outermost open element with one of the tags that is
+	// not outside a scope element.
+	implied := func(tags []string) *htmlNode {
+		var found *htmlNode
+		for n := current; n != nil && n.Type == htmlElementNode; n = n.Parent {
+			matched := false
+			for _, tag := range tags {
+				if n.Tag == tag {
+					found, matched = n, true
+				}
+			}
+			if !matched && htmlScopeElements[n.Tag] {
+				break
+			}
+		}
+		return found
+	}
+
+	for i := 0; i < len(s); {
+		if s[i] != '<' {
+			end := strings.IndexByte(s[i:], '<')
+			if end < 0 {
+				end = len(s) - i
+			}
+			current.appendChild(&htmlNode{Type: htmlTextNode, Data: html.UnescapeString(s[i : i+end])})
+			i += end
+			continue
+		}
+
+		rest := s[i:]
+		switch {
+		case strings.HasPrefix(rest, "<!--"):
+			end := strings.Index(rest[4:], "-->")
+			if end < 0 {
+				current.appendChild(&htmlNode{Type: htmlCommentNode, Data: rest[4:]})
+				i = len(s)
+				continue
+			}
+			current.appendChild(&htmlNode{Type: htmlCommentNode, Data: rest[4 : 4+end]})
+			i += 4 + end + 3
+
+		case strings.HasPrefix(rest, "<!") || strings.HasPrefix(rest, "<?"):
+			nodeType := htmlDoctypeNode
+			if rest[1] == '?' {
+				nodeType = htmlProcessingInstructionNode
+			}
+			end := strings.IndexByte(rest, '>')
+			if end < 0 {
+				current.appendChild(&htmlNode{Type: nodeType, Data: rest[2:]})
+				i = len(s)
+				continue
+			}
+			current.appendChild(&htmlNode{Type: nodeType, Data: rest[2:end]})
+			i += end + 1
+
+		case strings.HasPrefix(rest, "</") && len(rest) > 2 && isASCIILetter(rest[2]):
+			name, _ := scanTagName(rest[2:])
+			end := strings.IndexByte(rest, '>')
+			if end < 0 {
+				end = len(rest) - 1
+			}
+			if target := open(name); target != nil {
+				closeTo(target)
+			}
+			i += end + 1
+
+		case len(rest) > 1 && isASCIILetter(rest[1]):
+			node, n, selfClosing := scanStartTag(rest)
+			i += n
+			if closes, ok := htmlImpliedEnd[node.Tag]; ok {
+				if target := implied(closes); target != nil {
+					closeTo(target)
+				}
+			}
+			current.appendChild(node)
+			if htmlVoidElements[node.Tag] || selfClosing {
+				continue
+			}
+			if htmlRawTextElements[node.Tag] {
+				end := indexFold(s[i:], "</"+node.Tag)
+				if end < 0 {
+					end = len(s) - i
+				}
+				if text := s[i : i+end]; text != "" {
+					if node.Tag == "textarea" || node.Tag == "title" {
+						text = html.UnescapeString(text)
+					}
+					node.appendChild(&htmlNode{Type: htmlTextNode, Data: text})
+				}
+				i += end
+				if close := strings.IndexByte(s[i:], '>'); close >= 0 {
+					i += close + 1
+				} else {
+					i = len(s)
+				}
+				continue
+			}
+			current = node
+
+		default:
+			current.appendChild(&htmlNode{Type: htmlTextNode, Data: "<"})
+			i++
+		}
+	}
+	return doc
+}
+
+// scanTagName returns the lowercase tag name at the start of s and its
+// length.
+func scanTagName(s string) (string, int) {
+	n := 0
+	for n < len(s) && !isHTMLSpace(s[n]) && s[n] != '>' && s[n] != '/' {
+		n++
+	}
+	return strings.ToLower(s[:n]), n
+}
+
+// scanStartTag parses the start tag at the beginning of s. It returns the
+// element, the length of the tag and whether it is self-closing.
+func scanStartTag(s string) (*htmlNode, int, bool) {
+	name, n := scanTagName(s[1:])
+	node := &htmlNode{Type: htmlElementNode, Tag: name}
+	i := 1 + n
+	for i < len(s) {
+		for i < len(s) && (isHTMLSpace(s[i]) || s[i] == '/') {
+			if s[i] == '/' && i+1 < len(s) && s[i+1] == '>' {
+				return node, i + 2, true
+			}
+			i++
+		}
+		if i >= len(s) || s[i] == '>' {
+			i++
+			break
+		}
+
+		start := i
+		for i < len(s) && !isHTMLSpace(s[i]) && s[i] != '=' && s[i] != '>' && !(s[i] == '/' && i+1 < len(s) && s[i+1] == '>') {
+			i++
+		}
+		attr := htmlAttr{Key: strings.ToLower(s[start:i])}
+		for i < len(s) && isHTMLSpace(s[i]) {
+			i++
+		}
+		if i < len(s) && s[i] == '=' {
+			i++
+			for i < len(s) && isHTMLSpace(s[i]) {
+				i++
+			}
+			if i < len(s) && (s[i] == '"' || s[i] == '\'') {
+				quote := s[i]
+				end := strings.IndexByte(s[i+1:], quote)
+				if end < 0 {
+					end = len(s) - i - 1
+				}
+				attr.Value = html.UnescapeString(s[i+1 : i+1+end])
+				i += end + 2
+			} else {
+				start := i
+				for i < len(s) && !isHTMLSpace(s[i]) && s[i] != '>' {
+					i++
+				}
+				attr.Value = html.UnescapeString(s[start:i])
+			}
+		}
+		if _, exists := node.attr(attr.Key); !exists && attr.Key != "" {
+			node.Attrs = append(node.Attrs, attr)
+		}
+	}
+	return node, min(i, len(s)), false
+}
+
+func isASCIILetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func isHTMLSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
+
+// indexFold is strings.Index ignoring ASCII case.
+func indexFold(s, substr string) int {
+	for i := 0; i+len(substr) <= len(s); i++ {
+		if strings.EqualFold(s[i:i+len(substr)], substr) {
+			return i
+		}
+	}
+	return -1
+}
+
+// renderHTML serializes a node and its descendants.
+func renderHTML(n *htmlNode) string {
+	var b strings.Builder
+	n.render(&b)
+	return b.String()
+}
+
+func (n *htmlNode) render(b *strings.Builder) {
+	switch n.Type {
+	case htmlDocumentNode:
+		for _, child := range n.Children {
+			child.render(b)
+		}
+	case htmlTextNode:
+		if n.Parent != nil && (n.Parent.Tag == "script" || n.Parent.Tag == "style") {
+			b.WriteString(n.Data)
+		} else {
+			b.WriteString(htmlTextEscaper.Replace(n.Data))
+		}
+	case htmlCommentNode:
+		b.WriteString("<!--" + n.Data + "-->")
+	case htmlDoctypeNode:
+		b.WriteString("<!" + n.Data + ">")
+	case htmlProcessingInstructionNode:
+		b.WriteString("<?" + n.Data + ">")
+	case htmlElementNode:
+		b.WriteString("<" + n.Tag)
+		for _, attr := range n.Attrs {
+			b.WriteString(" " + attr.Key + `="` + htmlAttrEscaper.Replace(attr.Value) + `"`)
+		}
+		b.WriteByte('>')
+		if htmlVoidElements[n.Tag] {
+			return
+		}
+		for _, child := range n.Children {
+			child.render(b)
+		}
+		b.WriteString("</" + n.Tag + ">")
+	}
+}
+
+var (
+	htmlTextEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", "\u00a0", "&nbsp;")
+	htmlAttrEscaper = strings.NewReplacer("&", "&amp;", `"`, "&quot;", "\u00a0", "&nbsp;")
+)
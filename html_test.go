@@ -0,0 +1,112 @@
+package lettermint
+
+import (
+	"strings"
+	"testing"
+)
+
+// htmlOutline returns the element structure of a node, e.g. "ul(li(#) li(#))".
+func htmlOutline(n *htmlNode) string {
+	var parts []string
+	for _, child := range n.Children {
+		switch child.Type {
+		case htmlElementNode:
+			if len(child.Children) == 0 {
+				parts = append(parts, child.Tag)
+			} else {
+				parts = append(parts, child.Tag+"("+htmlOutline(child)+")")
+			}
+		case htmlTextNode:
+			if strings.TrimSpace(child.Data) != "" {
+				parts = append(parts, "#")
+			}
+		case htmlCommentNode:
+			parts = append(parts, "!")
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+func TestParseHTML(t *testing.T) {
+	tests := []struct {
+		html string
+		want string
+	}{
+		{"<ul><li>One<li>Two<ul><li>Nested</ul></ul>", "ul(li(#) li(# ul(li(#))))"},
+		{"<p>One<p>Two<div>Three</div>", "p(#) p(# div(#))"},
+		{"<table><tr><td>A<td>B<tr><td>C</table>", "table(tr(td(#) td(#)) tr(td(#)))"},
+		{"<p>Line<br>break<img src=x.png/></p>", "p(# br # img)"},
+		{"<div>Stray</span> end</div></p>", "div(# #)"},
+		{"<style>p > a { color: red }</style><!-- note --><script>if (a < b) {}</script>", "style(#) ! script(#)"},
+		{"<DIV CLASS=Box>a < b</DIV>", "div(# # #)"},
+		{"<!", ""},
+		{"<?", ""},
+		{"<!x", ""},
+		{"<p>Cut off<!", "p(#)"},
+		{"<p>Cut off</p", "p(#)"},
+		{"<a href=\"x", "a"},
+	}
+	for _, tt := range tests {
+		if got := htmlOutline(parseHTML(tt.html)); got != tt.want {
+			t.Errorf("parseHTML(%q) = %s, want %s", tt.html, got, tt.want)
+		}
+	}
+}
+
+func TestParseHTML_Attributes(t *testing.T) {
+	doc := parseHTML(`<a HREF="https://example.com/?a=1&amp;b=2" title='It&#39;s' data-x=plain disabled href="ignored">`)
+	a := doc.Children[0]
+	for key, want := range map[string]string{
+		"href":     "https://example.com/?a=1&b=2",
+		"title":    "It's",
+		"data-x":   "plain",
+		"disabled": "",
+	} {
+		if got, ok := a.attr(key); !ok || got != want {
+			t.Errorf("attr(%q) = %q, %v, want %q", key, got, ok, want)
+		}
+	}
+	if len(a.Attrs) != 4 {
+		t.Errorf("Attrs = %v, want duplicate attributes to be ignored", a.Attrs)
+	}
+}
+
+func TestRenderHTML(t *testing.T) {
+	tests := []struct {
+		html string
+		want string
+	}{
+		{
+			html: "<!DOCTYPE html><html><body><p class=x>A &amp; B &lt; C&nbsp;D<br/><img src='a.png' alt=\"Say &quot;hi&quot;\"></p><!-- c --></body></html>",
+			want: "<!DOCTYPE html><html><body><p class=\"x\">A &amp; B &lt; C&nbsp;D<br><img src=\"a.png\" alt=\"Say &quot;hi&quot;\"></p><!-- c --></body></html>",
+		},
+		{
+			html: "<ul><li>One<li>Two</ul><style>a > b { color: red }</style>",
+			want: "<ul><li>One</li><li>Two</li></ul><style>a > b { color: red }</style>",
+		},
+		{
+			html: "<?xml version=\"1.0\" encoding=\"utf-8\"?><!DOCTYPE html><html><body><?php echo 1; ?></body></html>",
+			want: "<?xml version=\"1.0\" encoding=\"utf-8\"?><!DOCTYPE html><html><body><?php echo 1; ?></body></html>",
+		},
+	}
+	for _, tt := range tests {
+		if got := renderHTML(parseHTML(tt.html)); got != tt.want {
+			t.Errorf("renderHTML(parseHTML(%q)) =\n%s\nwant\n%s", tt.html, got, tt.want)
+		}
+	}
+}
+
+func FuzzParseHTML(f *testing.F) {
+	for _, seed := range []string{
+		"<!DOCTYPE html><html><head><style>p { color: red }</style></head><body><p>Hi</p></body></html>",
+		"<ul><li>One<li>Two</ul><!-- c --><script>a < b</script>",
+		"<!", "<?", "<!x", "</", "<a href='x", "<style>p {",
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		renderHTML(parseHTML(s))
+		HTMLToText(s)
+		InlineCSS(s)
+	})
+}
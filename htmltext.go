@@ -0,0 +1,381 @@
+package lettermint
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// HTMLToText converts an HTML email body to plain text.
+//
+// Paragraphs and headings are separated by blank lines, level 1 and 2
+// headings are underlined, links are followed by their URL in parentheses,
+// list items are prefixed with "* " or their number, quotes with "> ", and
+// table cells are separated by " | ". Layout tables with a single cell per
+// row are rendered as paragraphs. The document head, scripts, styles and
+// hidden elements are skipped.
+//
+// The conversion is deterministic, so its output can be compared in tests.
+func HTMLToText(html string) string {
+	w := &textWriter{}
+	w.node(parseHTML(html))
+	return w.b.String()
+}
+
+// TextFromHTML derives the plain text body from the HTML body when the email
+// is sent, unless a text body is set. See HTMLToText and WithTextFromHTML.
+func (b *EmailBuilder) TextFromHTML() *EmailBuilder {
+	b.textFromHTML = true
+	return b
+}
+
+// textWriter writes the plain text of HTML nodes.
+type textWriter struct {
+	b        strings.Builder
+	prefixes []string  // line prefixes, e.g. "> " in quotes
+	shared   int       // prefixes of the last text that are still open
+	marker   string    // replaces the last prefix on the next line, e.g. "* "
+	newlines int       // line breaks to write before the next text
+	space    bool      // whether to write a space before the next text
+	midLine  bool      // whether the current line has text
+	pre      int       // depth of <pre> elements
+	list     *textList // innermost list
+}
+
+type textList struct {
+	ordered bool
+	next    int
+	parent  *textList
+}
+
+// textBlockElements are separated from their surroundings by a line break,
+// textParagraphElements by a blank line.
+var (
+	textBlockElements = map[string]bool{
+		"div": true, "section": true, "article": true, "header": true, "footer": true,
+		"main": true, "nav": true, "aside": true, "address": true, "center": true,
+		"form": true, "fieldset": true, "figcaption": true, "caption": true,
+		"details": true, "summary": true, "dt": true, "tr": true,
+	}
+	textParagraphElements = map[string]bool{
+		"p": true, "blockquote": true, "pre": true, "dl": true, "figure": true,
+		"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	}
+	textSkippedElements = map[string]bool{
+		"head": true, "script": true, "style": true, "title": true,
+		"template": true, "noscript": true,
+	}
+)
+
+// invisibleText removes zero-width characters used as spacers in emails.
+var invisibleText = strings.NewReplacer("\u200b", "", "\u200c", "", "\u200d", "", "\ufeff", "", "\u034f", "", "\u00ad", "")
+
+func (w *textWriter) node(n *htmlNode) {
+	switch n.Type {
+	case htmlDocumentNode:
+		w.children(n)
+	case htmlTextNode:
+		w.text(n.Data)
+	case htmlElementNode:
+		w.element(n)
+	}
+}
+
+func (w *textWriter) children(n *htmlNode) {
+	for _, child := range n.Children {
+		w.node(child)
+	}
+}
+
+func (w *textWriter) element(n *htmlNode) {
+	if textSkippedElements[n.Tag] || isHiddenElement(n) {
+		return
+	}
+
+	switch n.Tag {
+	case "br":
+		w.newlines++
+	case "hr":
+		w.block(2)
+		w.write(strings.Repeat("-", 40))
+		w.block(2)
+	case "img":
+		if alt, _ := n.attr("alt"); strings.TrimSpace(alt) != "" {
+			w.text(alt)
+		}
+	case "a":
+		w.link(n)
+	case "h1", "h2":
+		w.block(2)
+		heading := captureText(n)
+		w.write(heading)
+		if heading != "" {
+			underline := "="
+			if n.Tag == "h2" {
+				underline = "-"
+			}
+			w.block(1)
+			w.write(strings.Repeat(underline, utf8.RuneCountInString(heading)))
+		}
+		w.block(2)
+	case "ul", "ol":
+		w.listElement(n)
+	case "li":
+		w.listItem(n)
+	case "dd":
+		w.block(1)
+		w.indented("  ", n)
+		w.block(1)
+	case "blockquote":
+		w.block(2)
+		w.indented("> ", n)
+		w.block(2)
+	case "pre":
+		w.block(2)
+		w.pre++
+		w.children(n)
+		w.pre--
+		w.block(2)
+	case "table":
+		w.table(n)
+	default:
+		switch {
+		case textParagraphElements[n.Tag]:
+			w.block(2)
+			w.children(n)
+			w.block(2)
+		case textBlockElements[n.Tag]:
+			w.block(1)
+			w.children(n)
+			w.block(1)
+		default:
+			w.children(n)
+		}
+	}
+}
+
+// isHiddenElement reports whether an element is not displayed, like the
+// preheader text of many emails.
+func isHiddenElement(n *htmlNode) bool {
+	if _, hidden := n.attr("hidden"); hidden {
+		return true
+	}
+	style, _ := n.attr("style")
+	style = strings.ToLower(strings.Join(strings.Fields(style), ""))
+	return strings.Contains(style, "display:none")
+}
+
+func (w *textWriter) link(n *htmlNode) {
+	href, _ := n.attr("href")
+	href = strings.TrimSpace(href)
+	text := captureText(n)
+	lower := strings.ToLower(href)
+
+	switch {
+	case href == "" || strings.HasPrefix(href, "#") || strings.HasPrefix(lower, "javascript:"):
+		w.textLine(text)
+	case text == "":
+		w.textLine(strings.TrimPrefix(href, "mailto:"))
+	case text == href || text == strings.TrimPrefix(href, "mailto:") || text == strings.TrimPrefix(href, "tel:"):
+		w.textLine(text)
+	default:
+		w.textLine(text + " (" + href + ")")
+	}
+}
+
+func (w *textWriter) listElement(n *htmlNode) {
+	if w.list != nil {
+		w.block(1)
+	} else {
+		w.block(2)
+	}
+	list := &textList{ordered: n.Tag == "ol", next: 1, parent: w.list}
+	if start, err := strconv.Atoi(attrValue(n, "start")); err == nil && list.ordered {
+		list.next = start
+	}
+	w.list = list
+	w.children(n)
+	w.list = list.parent
+	if w.list != nil {
+		w.block(1)
+	} else {
+		w.block(2)
+	}
+}
+
+func (w *textWriter) listItem(n *htmlNode) {
+	w.block(1)
+	marker := "* "
+	if w.list != nil && w.list.ordered {
+		marker = strconv.Itoa(w.list.next) + ". "
+		w.list.next++
+	}
+	w.marker = marker
+	w.indented(strings.Repeat(" ", len(marker)), n)
+	w.marker = ""
+	w.block(1)
+}
+
+// indented writes the children of n with an additional line prefix.
+func (w *textWriter) indented(prefix string, n *htmlNode) {
+	w.shared = min(w.shared, len(w.prefixes))
+	w.prefixes = append(w.prefixes, prefix)
+	w.children(n)
+	w.prefixes = w.prefixes[:len(w.prefixes)-1]
+	w.shared = min(w.shared, len(w.prefixes))
+}
+
+// table writes the rows of a table on separate lines. Rows with more than
+// one non-empty cell are written as cells separated by " | "; other rows,
+// such as those of layout tables, are written as blocks.
+func (w *textWriter) table(n *htmlNode) {
+	w.block(2)
+	for _, row := range tableRows(n) {
+		var cells []string
+		block := false
+		for _, cell := range row.Children {
+			if cell.Type != htmlElementNode || cell.Tag != "td" && cell.Tag != "th" || isHiddenElement(cell) {
+				continue
+			}
+			sub := &textWriter{}
+			sub.children(cell)
+			if text := sub.b.String(); strings.TrimSpace(text) != "" {
+				cells = append(cells, text)
+				block = block || strings.Contains(text, "\n")
+			}
+		}
+
+		if len(cells) > 1 && !block {
+			w.block(1)
+			w.write(strings.Join(cells, " | "))
+			continue
+		}
+		for _, cell := range cells {
+			// Cells containing blocks are separated like paragraphs.
+			separator := 1
+			if block {
+				separator = 2
+			}
+			w.block(separator)
+			w.lines(cell)
+			w.block(separator)
+		}
+	}
+	w.block(2)
+}
+
+// tableRows returns the rows of a table, excluding those of nested tables.
+func tableRows(table *htmlNode) []*htmlNode {
+	var rows []*htmlNode
+	for _, child := range table.Children {
+		if child.Type != htmlElementNode {
+			continue
+		}
+		switch child.Tag {
+		case "tr":
+			rows = append(rows, child)
+		case "thead", "tbody", "tfoot":
+			for _, row := range child.Children {
+				if row.Type == htmlElementNode && row.Tag == "tr" {
+					rows = append(rows, row)
+				}
+			}
+		}
+	}
+	return rows
+}
+
+// captureText returns the text of the children of n on a single line.
+func captureText(n *htmlNode) string {
+	sub := &textWriter{}
+	sub.children(n)
+	return strings.Join(strings.Fields(sub.b.String()), " ")
+}
+
+// text writes the text of a text node, collapsing whitespace outside of
+// <pre> elements.
+func (w *textWriter) text(s string) {
+	s = invisibleText.Replace(s)
+	if w.pre > 0 {
+		w.lines(s)
+		return
+	}
+	if s == "" {
+		return
+	}
+	words := strings.Fields(s)
+	if len(words) == 0 {
+		w.space = true
+		return
+	}
+	if isHTMLSpace(s[0]) {
+		w.space = true
+	}
+	w.write(strings.Join(words, " "))
+	if isHTMLSpace(s[len(s)-1]) {
+		w.space = true
+	}
+}
+
+// textLine writes text that is already on a single line.
+func (w *textWriter) textLine(s string) {
+	if s != "" {
+		w.write(s)
+	}
+}
+
+// lines writes text verbatim, line by line.
+func (w *textWriter) lines(s string) {
+	for i, line := range strings.Split(s, "\n") {
+		if i > 0 {
+			w.newlines++
+		}
+		w.write(line)
+	}
+}
+
+// block requests a line break (1) or blank line (2) before the next text.
+func (w *textWriter) block(newlines int) {
+	w.newlines = max(w.newlines, newlines)
+}
+
+// write writes text, starting a new line first if requested.
+func (w *textWriter) write(s string) {
+	if s == "" {
+		return
+	}
+	if w.b.Len() == 0 {
+		w.newlines = 0
+	}
+	if w.newlines > 0 {
+		w.b.WriteByte('\n')
+		// Blank lines between blocks only keep the prefixes of the elements
+		// that contain both, so a quote is not extended to the line before.
+		blank := strings.TrimRight(strings.Join(w.prefixes[:w.shared], ""), " ")
+		for i := 1; i < w.newlines; i++ {
+			w.b.WriteString(blank + "\n")
+		}
+		w.newlines = 0
+		w.midLine = false
+	}
+
+	if !w.midLine {
+		if w.marker != "" && len(w.prefixes) > 0 {
+			w.b.WriteString(strings.Join(w.prefixes[:len(w.prefixes)-1], "") + w.marker)
+			w.marker = ""
+		} else {
+			w.b.WriteString(strings.Join(w.prefixes, ""))
+		}
+	} else if w.space {
+		w.b.WriteByte(' ')
+	}
+	w.space = false
+	w.midLine = true
+	w.shared = len(w.prefixes)
+	w.b.WriteString(s)
+}
+
+func attrValue(n *htmlNode, key string) string {
+	value, _ := n.attr(key)
+	return value
+}
@@ -0,0 +1,114 @@
+package lettermint
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHTMLToText(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		want string
+	}{
+		{
+			name: "paragraphs and line breaks",
+			html: "<p>Hello   <b>Jane</b>,</p>\n<p>Thanks for\nyour order.<br>It ships today.</p>",
+			want: "Hello Jane,\n\nThanks for your order.\nIt ships today.",
+		},
+		{
+			name: "headings",
+			html: "<h1>Receipt</h1><h2>Items</h2><h3>Notes</h3><p>None</p>",
+			want: "Receipt\n=======\n\nItems\n-----\n\nNotes\n\nNone",
+		},
+		{
+			name: "links",
+			html: `<p><a href="https://example.com/orders/1">View order</a>, ` +
+				`<a href="https://example.com">https://example.com</a>, ` +
+				`<a href="mailto:help@example.com">help@example.com</a>, ` +
+				`<a href="#top">top</a>, <a href="https://example.com/logo"><img src="logo.png" alt="Acme"></a></p>`,
+			want: "View order (https://example.com/orders/1), https://example.com, help@example.com, top, Acme (https://example.com/logo)",
+		},
+		{
+			name: "lists",
+			html: "<p>Steps:</p><ol start=\"3\"><li>Pack<li>Ship<ul><li>By air</li><li>By sea</li></ul></li></ol><ul><li>Done</li></ul>",
+			want: "Steps:\n\n3. Pack\n4. Ship\n   * By air\n   * By sea\n\n* Done",
+		},
+		{
+			name: "data table",
+			html: "<table><thead><tr><th>Item</th><th>Qty</th></tr></thead><tbody><tr><td>Mug</td><td>2</td></tr><tr><td>Tea</td><td>1</td></tr></tbody></table>",
+			want: "Item | Qty\nMug | 2\nTea | 1",
+		},
+		{
+			name: "layout tables",
+			html: `<table><tr><td><table><tr><td><p>First</p><p>Second</p></td></tr></table></td></tr>` +
+				`<tr><td></td><td>Footer</td></tr></table>`,
+			want: "First\n\nSecond\n\nFooter",
+		},
+		{
+			name: "quotes and preformatted text",
+			html: "<blockquote><p>Quoted</p><p>Twice</p></blockquote><pre>  a\n    b</pre>",
+			want: "> Quoted\n>\n> Twice\n\n  a\n    b",
+		},
+		{
+			name: "quote between paragraphs",
+			html: "<p>a</p><blockquote>b</blockquote><p>c</p><blockquote><p>d</p><blockquote>e</blockquote></blockquote><blockquote>f</blockquote>",
+			want: "a\n\n> b\n\nc\n\n> d\n>\n> > e\n\n> f",
+		},
+		{
+			name: "skipped content",
+			html: `<html><head><title>T</title><style>p{color:red}</style></head><body>` +
+				`<div style="display: none">Preheader&zwnj;&nbsp;</div><script>alert(1)</script><p>Visible &amp; kept</p><hr><p>End</p></body></html>`,
+			want: "Visible & kept\n\n----------------------------------------\n\nEnd",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := HTMLToText(tt.html); got != tt.want {
+				t.Errorf("HTMLToText() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEmailBuilder_TextFromHTML(t *testing.T) {
+	var got emailPayload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = emailPayload{}
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		_, _ = w.Write([]byte(`{"message_id":"msg_1","status":"pending"}`))
+	}))
+	defer server.Close()
+
+	send := func(client *Client, configure func(*EmailBuilder) *EmailBuilder) string {
+		t.Helper()
+		email := client.Email(context.Background()).
+			From("sender@example.com").
+			To("recipient@example.com").
+			Subject("Hello").
+			HTML(`<p>Hi <a href="https://example.com">there</a></p>`)
+		if _, err := configure(email).Send(); err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+		return got.Text
+	}
+
+	client, _ := New("test-token", WithBaseURL(server.URL))
+	if text := send(client, func(b *EmailBuilder) *EmailBuilder { return b }); text != "" {
+		t.Errorf("Text = %q without opting in, want none", text)
+	}
+	if text := send(client, (*EmailBuilder).TextFromHTML); text != "Hi there (https://example.com)" {
+		t.Errorf("Text = %q with TextFromHTML", text)
+	}
+
+	client, _ = New("test-token", WithBaseURL(server.URL), WithTextFromHTML())
+	if text := send(client, func(b *EmailBuilder) *EmailBuilder { return b }); text != "Hi there (https://example.com)" {
+		t.Errorf("Text = %q with WithTextFromHTML", text)
+	}
+	if text := send(client, func(b *EmailBuilder) *EmailBuilder { return b.Text("Custom") }); text != "Custom" {
+		t.Errorf("Text = %q, want the explicit text body to be kept", text)
+	}
+}
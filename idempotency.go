@@ -0,0 +1,115 @@
+package lettermint
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// IdempotencyKeyFunc derives the idempotency key for a send.
+//
+// emails holds the messages of the send: a single message for
+// EmailBuilder.Build, EmailBuilder.Send and Client.Send, and every message of
+// the batch for Client.SendBatch. The function is called once per logical
+// send, or once when a message is built; the returned key is reused for
+// every retry of that send. Returning an empty key sends the request without
+// an idempotency key.
+type IdempotencyKeyFunc func(ctx context.Context, emails []SendMailRequest) (string, error)
+
+// IdempotencyKeyFromMetadata returns an IdempotencyKeyFunc that derives the
+// key from your own entity IDs stored in the given metadata field.
+//
+// The key is a hash of the field's values across all messages of the send,
+// so the same entities always produce the same key. Sending a message without
+// the metadata field fails before any request is made.
+//
+// Example:
+//
+//	client, err := lettermint.New("your-api-token",
+//	    lettermint.WithIdempotencyKeyFunc(lettermint.IdempotencyKeyFromMetadata("order_id")),
+//	)
+func IdempotencyKeyFromMetadata(field string) IdempotencyKeyFunc {
+	return func(ctx context.Context, emails []SendMailRequest) (string, error) {
+		values := make([]string, 0, len(emails))
+		for i, email := range emails {
+			value := email.Metadata[field]
+			if value == "" {
+				return "", fmt.Errorf("message %d has no %q metadata to derive an idempotency key from", i, field)
+			}
+			values = append(values, value)
+		}
+		sum := sha256.Sum256([]byte(field + "\x00" + strings.Join(values, "\x00")))
+		return hex.EncodeToString(sum[:]), nil
+	}
+}
+
+// idempotencyHeader returns the headers carrying the idempotency key for a
+// send. An explicitly provided key takes precedence over the client's
+// key function.
+func (c *Client) idempotencyHeader(ctx context.Context, key string, emails []SendMailRequest) (http.Header, error) {
+	header := http.Header{}
+	if key == "" && c.idempotencyKeys != nil {
+		generated, err := c.idempotencyKeys(ctx, emails)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidRequest, err)
+		}
+		key = generated
+	}
+	if key != "" {
+		header.Set("Idempotency-Key", key)
+	}
+	return header, nil
+}
+
+// randomIdempotencyKey generates a random UUID (version 4) for each send.
+func randomIdempotencyKey(context.Context, []SendMailRequest) (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", fmt.Errorf("failed to generate idempotency key: %w", err)
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
+
+// sendMailRequest converts the builder payload to its public API shape.
+func (p *emailPayload) sendMailRequest() SendMailRequest {
+	req := SendMailRequest{
+		Route:    p.Route,
+		From:     p.From,
+		Subject:  p.Subject,
+		To:       p.To,
+		Cc:       p.CC,
+		Bcc:      p.BCC,
+		ReplyTo:  p.ReplyTo,
+		Headers:  p.Headers,
+		Metadata: p.Metadata,
+	}
+	if p.Tag != "" {
+		req.Tag = &p.Tag
+	}
+	if p.HTML != "" {
+		req.HTML = &p.HTML
+	}
+	if p.Text != "" {
+		req.Text = &p.Text
+	}
+	for _, attachment := range p.Attachments {
+		item := map[string]interface{}{
+			"filename": attachment.Filename,
+			"content":  attachment.Content,
+		}
+		if attachment.ContentType != "" {
+			item["content_type"] = attachment.ContentType
+		}
+		if attachment.ContentID != "" {
+			item["content_id"] = attachment.ContentID
+		}
+		req.Attachments = append(req.Attachments, item)
+	}
+	return req
+}
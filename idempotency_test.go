@@ -0,0 +1,156 @@
+package lettermint
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+)
+
+func TestWithIdempotencyKeys_ReusesKeyAcrossRetries(t *testing.T) {
+	var keys []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.Header.Get("Idempotency-Key"))
+		if len(keys) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(SendResponse{MessageID: "msg_123", Status: "queued"})
+	}))
+	defer server.Close()
+
+	client, _ := New("test-token",
+		WithBaseURL(server.URL),
+		WithRetryPolicy(testRetryPolicy()),
+		WithIdempotencyKeys(),
+	)
+
+	if _, err := client.Email(context.Background()).
+		From("sender@example.com").
+		To("recipient@example.com").
+		Subject("Test").
+		Text("Body").
+		Send(); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	if len(keys) != 2 {
+		t.Fatalf("attempts = %d, want 2", len(keys))
+	}
+	uuid := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	if !uuid.MatchString(keys[0]) {
+		t.Fatalf("Idempotency-Key = %q, want a UUID", keys[0])
+	}
+	if keys[1] != keys[0] {
+		t.Fatalf("retry Idempotency-Key = %q, want %q", keys[1], keys[0])
+	}
+}
+
+func TestWithIdempotencyKeys_NewKeyPerSend(t *testing.T) {
+	var keys []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.Header.Get("Idempotency-Key"))
+		_ = json.NewEncoder(w).Encode(SendResponse{MessageID: "msg_123", Status: "queued"})
+	}))
+	defer server.Close()
+
+	client, _ := New("test-token", WithBaseURL(server.URL), WithIdempotencyKeys())
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.Email(context.Background()).
+			From("sender@example.com").
+			To("recipient@example.com").
+			Subject("Test").
+			Text("Body").
+			Send(); err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+	}
+
+	if keys[0] == "" || keys[0] == keys[1] {
+		t.Fatalf("Idempotency-Key values = %#v, want two distinct keys", keys)
+	}
+}
+
+func TestWithIdempotencyKeys_ExplicitKeyTakesPrecedence(t *testing.T) {
+	var key string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key = r.Header.Get("Idempotency-Key")
+		_ = json.NewEncoder(w).Encode(SendResponse{MessageID: "msg_123", Status: "queued"})
+	}))
+	defer server.Close()
+
+	client, _ := New("test-token", WithBaseURL(server.URL), WithIdempotencyKeys())
+
+	if _, err := client.Email(context.Background()).
+		From("sender@example.com").
+		To("recipient@example.com").
+		Subject("Test").
+		Text("Body").
+		IdempotencyKey("explicit-key").
+		Send(); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	if key != "explicit-key" {
+		t.Fatalf("Idempotency-Key = %q, want explicit-key", key)
+	}
+}
+
+func TestWithIdempotencyKeyFunc_SendBatch(t *testing.T) {
+	var keys []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.Header.Get("Idempotency-Key"))
+		_ = json.NewEncoder(w).Encode([]SendResponse{{MessageID: "msg_123", Status: "queued"}})
+	}))
+	defer server.Close()
+
+	client, _ := New("test-token",
+		WithBaseURL(server.URL),
+		WithIdempotencyKeyFunc(IdempotencyKeyFromMetadata("order_id")),
+	)
+
+	batch := SendBatchMailRequest{
+		{From: "sender@example.com", To: []string{"a@example.com"}, Subject: "A", Metadata: map[string]string{"order_id": "1"}},
+		{From: "sender@example.com", To: []string{"b@example.com"}, Subject: "B", Metadata: map[string]string{"order_id": "2"}},
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := client.SendBatch(context.Background(), batch); err != nil {
+			t.Fatalf("SendBatch() error = %v", err)
+		}
+	}
+
+	if keys[0] == "" || keys[0] != keys[1] {
+		t.Fatalf("Idempotency-Key values = %#v, want the same derived key", keys)
+	}
+
+	batch[1].Metadata = nil
+	_, err := client.SendBatch(context.Background(), batch)
+	if !errors.Is(err, ErrInvalidRequest) {
+		t.Fatalf("SendBatch() without metadata error = %v, want ErrInvalidRequest", err)
+	}
+	if len(keys) != 2 {
+		t.Fatalf("requests = %d, want no request for invalid batch", len(keys))
+	}
+}
+
+func TestIdempotencyKeyFromMetadata(t *testing.T) {
+	keyFunc := IdempotencyKeyFromMetadata("order_id")
+
+	first, err := keyFunc(context.Background(), []SendMailRequest{{Metadata: map[string]string{"order_id": "42"}}})
+	if err != nil {
+		t.Fatalf("keyFunc() error = %v", err)
+	}
+	second, _ := keyFunc(context.Background(), []SendMailRequest{{Metadata: map[string]string{"order_id": "42"}}})
+	other, _ := keyFunc(context.Background(), []SendMailRequest{{Metadata: map[string]string{"order_id": "43"}}})
+
+	if first != second {
+		t.Fatalf("keys for the same entity differ: %q != %q", first, second)
+	}
+	if first == other {
+		t.Fatalf("keys for different entities are equal: %q", first)
+	}
+}
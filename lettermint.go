@@ -2,6 +2,7 @@ package lettermint
 
 import (
 	"context"
+	"log/slog"
 	"net/http"
 	"time"
 )
@@ -22,10 +23,22 @@ const (
 // The client is safe for concurrent use by multiple goroutines.
 // Create a new client using the New function.
 type Client struct {
-	apiToken   string
-	baseURL    string
-	httpClient *http.Client
-	authScheme authenticationScheme
+	tokenSource      TokenSource
+	baseURL          string
+	httpClient       *http.Client
+	authScheme       authenticationScheme
+	retryPolicy      RetryPolicy
+	idempotencyKeys  IdempotencyKeyFunc
+	rateLimiter      *rateLimiter
+	circuitBreaker   *circuitBreaker
+	maxResponseBytes int64
+	attachmentPolicy *attachmentPolicy
+	textFromHTML     bool
+	middleware       []Middleware
+	middlewareClient *http.Client // httpClient with the middleware applied
+	observers        []Observer
+	logger           *slog.Logger
+	logConfig        LogConfig
 }
 
 type authenticationScheme string
@@ -57,23 +70,44 @@ func New(apiToken string, opts ...Option) (*Client, error) {
 	return newClient(apiToken, authSchemeSending, opts...)
 }
 
+// NewWithTokenSource creates a new Lettermint client that obtains its API
+// token from source before every request.
+//
+// Use this to rotate the token without recreating the client:
+//
+//	client, err := lettermint.NewWithTokenSource(
+//	    lettermint.FileTokenSource("/var/run/secrets/lettermint/token"),
+//	)
+func NewWithTokenSource(source TokenSource, opts ...Option) (*Client, error) {
+	return newTokenSourceClient(source, authSchemeSending, opts...)
+}
+
 func newClient(apiToken string, authScheme authenticationScheme, opts ...Option) (*Client, error) {
 	if apiToken == "" {
 		return nil, ErrInvalidAPIToken
 	}
+	return newTokenSourceClient(StaticTokenSource(apiToken), authScheme, opts...)
+}
+
+func newTokenSourceClient(source TokenSource, authScheme authenticationScheme, opts ...Option) (*Client, error) {
+	if source == nil {
+		return nil, ErrInvalidAPIToken
+	}
 
 	c := &Client{
-		apiToken:   apiToken,
-		baseURL:    DefaultBaseURL,
-		authScheme: authScheme,
+		tokenSource: source,
+		baseURL:     DefaultBaseURL,
+		authScheme:  authScheme,
 		httpClient: &http.Client{
 			Timeout: DefaultTimeout,
 		},
+		logConfig: DefaultLogConfig(),
 	}
 
 	for _, opt := range opts {
 		opt(c)
 	}
+	c.middlewareClient = c.wrapMiddleware()
 
 	return c, nil
 }
@@ -0,0 +1,316 @@
+package lettermint
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// LogConfig configures what the client logs.
+//
+// Secrets such as the API token, the Authorization header and tokens in
+// response bodies are never logged. Recipient addresses, subjects and bodies
+// are masked unless RevealPII is set.
+type LogConfig struct {
+	// RequestLevel is the level of request summaries.
+	RequestLevel slog.Level
+
+	// ResponseLevel is the level of response summaries.
+	ResponseLevel slog.Level
+
+	// ErrorLevel is the level of failed attempts.
+	ErrorLevel slog.Level
+
+	// IncludeHeaders adds request headers to request summaries.
+	IncludeHeaders bool
+
+	// IncludeBodies adds request bodies to request summaries and error
+	// response bodies to failed attempts.
+	IncludeBodies bool
+
+	// RevealPII disables masking of email addresses, subjects and message
+	// content in logged bodies, of email addresses in logged errors, and of
+	// query parameter values in the URLs of logged transport errors.
+	RevealPII bool
+
+	// MaxBodyBytes truncates logged bodies, including APIError.ResponseBody,
+	// and logged error messages. Zero or negative values use 1024.
+	MaxBodyBytes int
+}
+
+// DefaultLogConfig returns the configuration used by WithLogger.
+//
+// Requests and responses are logged at debug level and failed attempts at
+// warn level. Headers and bodies are not logged.
+func DefaultLogConfig() LogConfig {
+	return LogConfig{
+		RequestLevel:  slog.LevelDebug,
+		ResponseLevel: slog.LevelDebug,
+		ErrorLevel:    slog.LevelWarn,
+		MaxBodyBytes:  1024,
+	}
+}
+
+const redacted = "[REDACTED]"
+
+// secretHeaders are never logged.
+var secretHeaders = map[string]bool{
+	"Authorization":       true,
+	"X-Lettermint-Token":  true,
+	"Cookie":              true,
+	"Proxy-Authorization": true,
+}
+
+// secretFields are body fields that are never logged, even with RevealPII.
+var secretFields = map[string]bool{
+	"token":          true,
+	"new_token":      true,
+	"api_token":      true,
+	"secret":         true,
+	"signing_secret": true,
+	"password":       true,
+}
+
+// piiFields are body fields that are masked unless RevealPII is set.
+var piiFields = map[string]bool{
+	"from":      true,
+	"to":        true,
+	"cc":        true,
+	"bcc":       true,
+	"reply_to":  true,
+	"email":     true,
+	"recipient": true,
+	"subject":   true,
+	"html":      true,
+	"text":      true,
+	"content":   true,
+	"headers":   true,
+	"metadata":  true,
+}
+
+func (c *Client) logRequest(ctx context.Context, op *Operation, req *http.Request, body bodySource, attempt int) {
+	if c.logger == nil || !c.logger.Enabled(ctx, c.logConfig.RequestLevel) {
+		return
+	}
+	attrs := []slog.Attr{
+		slog.String("operation", op.Name),
+		slog.String("method", req.Method),
+		slog.String("path", op.Path),
+		slog.Int("attempt", attempt),
+	}
+	if c.logConfig.IncludeHeaders {
+		attrs = append(attrs, slog.Any("headers", logHeaders(req.Header)))
+	}
+	if c.logConfig.IncludeBodies && body != nil {
+		if data := body.logBytes(); len(data) > 0 {
+			attrs = append(attrs, slog.String("body", c.logBody(data)))
+		}
+	}
+	c.logger.LogAttrs(ctx, c.logConfig.RequestLevel, "lettermint request", attrs...)
+}
+
+func (c *Client) logResponse(ctx context.Context, op *Operation, resp *http.Response, attempt int, elapsed time.Duration) {
+	if c.logger == nil || !c.logger.Enabled(ctx, c.logConfig.ResponseLevel) {
+		return
+	}
+	c.logger.LogAttrs(ctx, c.logConfig.ResponseLevel, "lettermint response",
+		slog.String("operation", op.Name),
+		slog.String("method", op.Method),
+		slog.String("path", op.Path),
+		slog.Int("attempt", attempt),
+		slog.Int("status", resp.StatusCode),
+		slog.Duration("duration", elapsed),
+	)
+}
+
+func (c *Client) logFailure(ctx context.Context, op *Operation, attempt int, err error) {
+	if c.logger == nil || !c.logger.Enabled(ctx, c.logConfig.ErrorLevel) {
+		return
+	}
+	attrs := []slog.Attr{
+		slog.String("operation", op.Name),
+		slog.String("method", op.Method),
+		slog.String("path", op.Path),
+		slog.Int("attempt", attempt),
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		attrs = append(attrs,
+			slog.Int("status", apiErr.StatusCode),
+			slog.String("error", c.logError(err)),
+		)
+		if c.logConfig.IncludeBodies && apiErr.ResponseBody != "" {
+			attrs = append(attrs, slog.String("response_body", c.logBody([]byte(apiErr.ResponseBody))))
+		}
+	} else {
+		attrs = append(attrs, slog.String("error", c.logError(err)))
+	}
+	c.logger.LogAttrs(ctx, c.logConfig.ErrorLevel, "lettermint request failed", attrs...)
+}
+
+// logError returns the message of an error for logging. Unless RevealPII
+// is set, query parameter values are redacted from the URL of a transport
+// error, since they may contain email addresses, and email addresses in the
+// message, such as those an API error repeats, are masked. The message is
+// truncated to MaxBodyBytes, since API errors without a JSON body carry the
+// whole response body.
+func (c *Client) logError(err error) string {
+	message := err.Error()
+	if !c.logConfig.RevealPII {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) && urlErr.URL != "" {
+			message = strings.ReplaceAll(message, urlErr.URL, redactQuery(urlErr.URL))
+		}
+		message = maskEmails(message)
+	}
+	return truncate(message, c.logConfig.MaxBodyBytes)
+}
+
+// redactQuery returns the URL with the values of its query parameters
+// redacted.
+func redactQuery(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.RawQuery == "" {
+		return rawURL
+	}
+	params := strings.Split(u.RawQuery, "&")
+	for i, param := range params {
+		key, _, _ := strings.Cut(param, "=")
+		params[i] = key + "=" + redacted
+	}
+	u.RawQuery = strings.Join(params, "&")
+	return u.String()
+}
+
+// maskEmails masks the email addresses in free text with maskString.
+func maskEmails(text string) string {
+	var b strings.Builder
+	for {
+		at := strings.IndexByte(text, '@')
+		if at < 0 {
+			b.WriteString(text)
+			return b.String()
+		}
+		start := at
+		for start > 0 && isEmailByte(text[start-1]) {
+			start--
+		}
+		end := at + 1
+		for end < len(text) && isEmailByte(text[end]) {
+			end++
+		}
+		for end > at+1 && text[end-1] == '.' {
+			end--
+		}
+		b.WriteString(text[:start])
+		if start < at && end > at+1 {
+			b.WriteString(maskString(text[start:end]))
+		} else {
+			b.WriteString(text[start:end])
+		}
+		text = text[end:]
+	}
+}
+
+func isEmailByte(c byte) bool {
+	return c > ' ' && c != 0x7f && !strings.ContainsRune(`"'<>()[],;:@`, rune(c))
+}
+
+// logHeaders returns the headers with secret values redacted.
+func logHeaders(header http.Header) map[string]string {
+	out := make(map[string]string, len(header))
+	for key, values := range header {
+		if secretHeaders[http.CanonicalHeaderKey(key)] {
+			out[key] = redacted
+			continue
+		}
+		out[key] = strings.Join(values, ", ")
+	}
+	return out
+}
+
+// logBody masks and truncates a body for logging.
+func (c *Client) logBody(body []byte) string {
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		if !c.logConfig.RevealPII {
+			return fmt.Sprintf("[%d bytes]", len(body))
+		}
+		return truncate(string(body), c.logConfig.MaxBodyBytes)
+	}
+
+	masked, err := json.Marshal(maskValue(value, "", c.logConfig.RevealPII))
+	if err != nil {
+		return fmt.Sprintf("[%d bytes]", len(body))
+	}
+	return truncate(string(masked), c.logConfig.MaxBodyBytes)
+}
+
+// maskValue redacts secret fields and, unless revealPII is set, masks
+// personal data in a decoded JSON value.
+func maskValue(value interface{}, field string, revealPII bool) interface{} {
+	if secretFields[field] {
+		return redacted
+	}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			if !revealPII && piiFields[field] {
+				out[key] = maskValue(item, field, revealPII)
+				continue
+			}
+			out[key] = maskValue(item, strings.ToLower(key), revealPII)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[i] = maskValue(item, field, revealPII)
+		}
+		return out
+	case string:
+		if revealPII || !piiFields[field] {
+			return v
+		}
+		return maskString(v)
+	default:
+		if !revealPII && piiFields[field] && v != nil {
+			return redacted
+		}
+		return v
+	}
+}
+
+// maskString masks a personal value. Email addresses keep their domain so
+// logs remain useful for debugging delivery issues.
+func maskString(value string) string {
+	if at := strings.LastIndex(value, "@"); at > 0 {
+		domain := strings.TrimSuffix(value[at+1:], ">")
+		if domain != "" && !strings.ContainsAny(domain, " \t\r\n") {
+			return "***@" + domain
+		}
+	}
+	return redacted
+}
+
+func truncate(value string, max int) string {
+	if max <= 0 {
+		max = 1024
+	}
+	if len(value) <= max {
+		return value
+	}
+	cut := max
+	for cut > 0 && !utf8.RuneStart(value[cut]) {
+		cut--
+	}
+	return value[:cut] + fmt.Sprintf("...[truncated %d bytes]", len(value)-cut)
+}
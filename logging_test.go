@@ -0,0 +1,215 @@
+package lettermint
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWithLogger_NeverLogsTokens(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(SendResponse{MessageID: "msg_123", Status: "queued"})
+	}))
+	defer server.Close()
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	config := DefaultLogConfig()
+	config.IncludeHeaders = true
+	config.IncludeBodies = true
+
+	client, _ := New("secret-sending-token", WithBaseURL(server.URL), WithLogger(logger), WithLogConfig(config))
+	if _, err := client.Email(context.Background()).
+		From("sender@example.com").
+		To("recipient@example.com").
+		Subject("Your password reset").
+		HTML("<p>Reset link</p>").
+		Header("X-Account", "acct_42").
+		Send(); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	api, _ := NewAPI("secret-api-token", WithBaseURL(server.URL), WithLogger(logger), WithLogConfig(config))
+	if _, err := api.Ping(context.Background()); err != nil {
+		t.Fatalf("Ping() error = %v", err)
+	}
+
+	output := buf.String()
+	for _, secret := range []string{"secret-sending-token", "secret-api-token", "recipient@", "sender@", "password reset", "Reset link", "acct_42"} {
+		if strings.Contains(output, secret) {
+			t.Errorf("log output contains %q:\n%s", secret, output)
+		}
+	}
+	for _, want := range []string{"lettermint request", "lettermint response", "operation=Email.Send", "operation=Ping", "***@example.com", "status=200"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("log output missing %q:\n%s", want, output)
+		}
+	}
+}
+
+func TestWithLogger_RevealPII(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(SendResponse{MessageID: "msg_123", Status: "queued"})
+	}))
+	defer server.Close()
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	config := DefaultLogConfig()
+	config.IncludeBodies = true
+	config.RevealPII = true
+
+	client, _ := New("test-token", WithBaseURL(server.URL), WithLogger(logger), WithLogConfig(config))
+	if _, err := client.Email(context.Background()).
+		From("sender@example.com").
+		To("recipient@example.com").
+		Subject("Quarterly numbers").
+		Text("Body").
+		Send(); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	output := buf.String()
+	for _, want := range []string{"recipient@example.com", "Quarterly numbers"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("log output missing %q:\n%s", want, output)
+		}
+	}
+}
+
+func TestWithLogger_TruncatesErrorResponseBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte(`{"message":"` + strings.Repeat("x", 500) + `","new_token":"leaked"}`))
+	}))
+	defer server.Close()
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+	config := DefaultLogConfig()
+	config.IncludeBodies = true
+	config.MaxBodyBytes = 64
+
+	api, _ := NewAPI("api-token", WithBaseURL(server.URL), WithLogger(logger), WithLogConfig(config))
+	if _, err := api.Team.Retrieve(context.Background()); err == nil {
+		t.Fatal("Team.Retrieve() expected error, got nil")
+	}
+
+	output := buf.String()
+	if !strings.Contains(output, "lettermint request failed") || !strings.Contains(output, "status=500") {
+		t.Fatalf("log output missing failure summary:\n%s", output)
+	}
+	if !strings.Contains(output, "truncated") {
+		t.Fatalf("log output did not truncate response body:\n%s", output)
+	}
+	if strings.Contains(output, "leaked") {
+		t.Fatalf("log output contains secret:\n%s", output)
+	}
+}
+
+func TestWithLogger_MasksErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		_, _ = w.Write([]byte(`{"message":"jane.doe@example.com is already suppressed."}`))
+	}))
+	defer server.Close()
+	unreachable := httptest.NewServer(http.NotFoundHandler())
+	unreachable.Close()
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+	query := map[string]string{"filter[email]": "jane.doe@example.com"}
+
+	api, _ := NewAPI("api-token", WithBaseURL(unreachable.URL), WithLogger(logger))
+	if _, err := api.Suppressions.List(context.Background(), query); err == nil {
+		t.Fatal("Suppressions.List() expected error, got nil")
+	}
+	api, _ = NewAPI("api-token", WithBaseURL(server.URL), WithLogger(logger))
+	if _, err := api.Suppressions.List(context.Background(), query); err == nil {
+		t.Fatal("Suppressions.List() expected error, got nil")
+	}
+
+	output := buf.String()
+	for _, secret := range []string{"jane.doe", "jane.doe%40example.com"} {
+		if strings.Contains(output, secret) {
+			t.Errorf("log output contains %q:\n%s", secret, output)
+		}
+	}
+	for _, want := range []string{"filter%5Bemail%5D=" + redacted, "***@example.com is already suppressed."} {
+		if !strings.Contains(output, want) {
+			t.Errorf("log output missing %q:\n%s", want, output)
+		}
+	}
+
+	buf.Reset()
+	config := DefaultLogConfig()
+	config.RevealPII = true
+	api, _ = NewAPI("api-token", WithBaseURL(unreachable.URL), WithLogger(logger), WithLogConfig(config))
+	_, _ = api.Suppressions.List(context.Background(), query)
+	if !strings.Contains(buf.String(), "jane.doe%40example.com") {
+		t.Errorf("log output with RevealPII missing the query:\n%s", buf.String())
+	}
+}
+
+func TestWithLogger_TruncatesErrors(t *testing.T) {
+	page := "<html><body>" + strings.Repeat("Bad gateway. ", 1000) + "</body></html>"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.WriteHeader(http.StatusBadGateway)
+		_, _ = w.Write([]byte(page))
+	}))
+	defer server.Close()
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+	config := DefaultLogConfig()
+	config.MaxBodyBytes = 64
+
+	api, _ := NewAPI("api-token", WithBaseURL(server.URL), WithLogger(logger), WithLogConfig(config))
+	if _, err := api.Team.Retrieve(context.Background()); err == nil {
+		t.Fatal("Team.Retrieve() expected error, got nil")
+	}
+
+	output := buf.String()
+	if !strings.Contains(output, "...[truncated") {
+		t.Errorf("log output missing truncation marker:\n%s", output)
+	}
+	if len(output) > 512 {
+		t.Errorf("log output is %d bytes, want the error truncated to MaxBodyBytes", len(output))
+	}
+}
+
+func TestMaskValue(t *testing.T) {
+	input := map[string]interface{}{
+		"from":      "John Doe <john@example.com>",
+		"to":        []interface{}{"a@example.com", "b@example.org"},
+		"subject":   "Invoice",
+		"tag":       "billing",
+		"metadata":  map[string]interface{}{"user_id": "42"},
+		"new_token": "secret",
+	}
+
+	got := maskValue(input, "", false).(map[string]interface{})
+	if got["from"] != "***@example.com" {
+		t.Errorf("from = %v", got["from"])
+	}
+	if to := got["to"].([]interface{}); to[0] != "***@example.com" || to[1] != "***@example.org" {
+		t.Errorf("to = %v", to)
+	}
+	if got["subject"] != redacted || got["tag"] != "billing" {
+		t.Errorf("subject = %v, tag = %v", got["subject"], got["tag"])
+	}
+	if got["metadata"].(map[string]interface{})["user_id"] != redacted {
+		t.Errorf("metadata = %v", got["metadata"])
+	}
+
+	revealed := maskValue(input, "", true).(map[string]interface{})
+	if revealed["subject"] != "Invoice" || revealed["new_token"] != redacted {
+		t.Errorf("revealed subject = %v, new_token = %v", revealed["subject"], revealed["new_token"])
+	}
+}
@@ -0,0 +1,1130 @@
+package lettermint
+
+import (
+	"html"
+	htmltemplate "html/template"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MarkdownToHTML converts Markdown to HTML.
+//
+// It supports the CommonMark block and inline elements (paragraphs,
+// headings, quotes, lists, code blocks, thematic breaks, emphasis, code
+// spans, links, images and hard line breaks) as well as GitHub tables and
+// ~~strikethrough~~. HTML in the source is escaped rather than passed
+// through, and links and images with schemes other than http, https,
+// mailto, tel and cid (images only) are rendered as plain text, so the
+// result is safe to send even if the source is not trusted.
+func MarkdownToHTML(src string) string {
+	src = strings.NewReplacer("\r\n", "\n", "\r", "\n", "\x00", "�").Replace(src)
+	lines := strings.Split(src, "\n")
+	for i, line := range lines {
+		lines[i] = expandTabs(line)
+	}
+
+	p := &markdownParser{refs: map[string]markdownLink{}}
+	blocks := p.blocks(lines)
+	var b strings.Builder
+	p.render(&b, blocks, false)
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// MarkdownOption configures the rendering of a Markdown body.
+type MarkdownOption func(*markdownOptions)
+
+type markdownOptions struct {
+	layout *htmltemplate.Template
+	data   interface{}
+}
+
+// MarkdownLayout wraps the HTML rendered from Markdown in a layout. The
+// layout is executed with a MarkdownLayoutData holding the content and
+// data.
+//
+// Example layout:
+//
+//	<html><body style="font-family: sans-serif">{{.Content}}<p>{{.Data.Footer}}</p></body></html>
+func MarkdownLayout(layout *htmltemplate.Template, data interface{}) MarkdownOption {
+	return func(o *markdownOptions) {
+		o.layout = layout
+		o.data = data
+	}
+}
+
+// MarkdownLayoutData is the data a Markdown layout is executed with.
+type MarkdownLayoutData struct {
+	// Content is the HTML rendered from Markdown.
+	Content htmltemplate.HTML
+
+	// Data is the data passed to MarkdownLayout.
+	Data interface{}
+}
+
+// Markdown sets the HTML body to the Markdown src converted to HTML, and
+// the plain text body to the text of that HTML. See MarkdownToHTML and
+// HTMLToText.
+//
+// With MarkdownLayout, the HTML body is wrapped in a layout; the text body
+// only contains the text of the Markdown. The layout is executed
+// immediately; an error is returned by Send as a *TemplateError.
+func (b *EmailBuilder) Markdown(src string, opts ...MarkdownOption) *EmailBuilder {
+	var o markdownOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	content := MarkdownToHTML(src)
+	body := content
+	if o.layout != nil {
+		rendered, ok := b.render("html", o.layout, MarkdownLayoutData{Content: htmltemplate.HTML(content), Data: o.data})
+		if !ok {
+			return b
+		}
+		body = rendered
+	}
+	b.payload.HTML = body
+	b.payload.Text = HTMLToText(content)
+	return b
+}
+
+// markdownParser parses Markdown into blocks, collecting link reference
+// definitions, and renders them.
+type markdownParser struct {
+	refs map[string]markdownLink
+}
+
+type markdownLink struct {
+	url, title string
+}
+
+type markdownBlockKind int
+
+const (
+	markdownParagraph markdownBlockKind = iota
+	markdownHeading
+	markdownThematicBreak
+	markdownCode
+	markdownQuote
+	markdownList
+	markdownItem
+	markdownTable
+)
+
+// markdownBlock is a block of a Markdown document.
+type markdownBlock struct {
+	kind        markdownBlockKind
+	text        string // inline text of paragraphs and headings, content of code blocks
+	level       int    // heading level
+	lang        string // code block language
+	children    []*markdownBlock
+	ordered     bool
+	start       int
+	tight       bool
+	rows        [][]string // table rows, starting with the header
+	align       []string
+	blankBefore bool // whether a blank line precedes the block
+}
+
+// blocks parses lines into blocks.
+func (p *markdownParser) blocks(lines []string) []*markdownBlock {
+	var (
+		blocks []*markdownBlock
+		para   []string
+		blank  bool
+	)
+	add := func(block *markdownBlock) {
+		block.blankBefore = blank
+		blank = false
+		blocks = append(blocks, block)
+	}
+	flush := func() {
+		if len(para) == 0 {
+			return
+		}
+		text := p.definitions(strings.Join(para, "\n"))
+		para = nil
+		if strings.TrimSpace(text) != "" {
+			add(&markdownBlock{kind: markdownParagraph, text: text})
+		}
+	}
+
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		indent := leadingSpaces(line)
+		trimmed := line[indent:]
+
+		switch {
+		case trimmed == "":
+			flush()
+			blank = len(blocks) > 0
+			i++
+
+		case indent >= 4 && len(para) > 0:
+			para = append(para, trimmed)
+			i++
+
+		case indent >= 4:
+			var code []string
+			for ; i < len(lines) && (isBlankLine(lines[i]) || leadingSpaces(lines[i]) >= 4); i++ {
+				code = append(code, lines[i][min(4, len(lines[i])):])
+			}
+			for len(code) > 0 && strings.TrimSpace(code[len(code)-1]) == "" {
+				code = code[:len(code)-1]
+			}
+			add(&markdownBlock{kind: markdownCode, text: strings.Join(code, "\n") + "\n"})
+
+		case isFence(trimmed):
+			flush()
+			fence := trimmed[:len(trimmed)-len(strings.TrimLeft(trimmed, trimmed[:1]))]
+			info := strings.Fields(html.UnescapeString(trimmed[len(fence):]))
+			block := &markdownBlock{kind: markdownCode}
+			if len(info) > 0 {
+				block.lang = info[0]
+			}
+			var code []string
+			for i++; i < len(lines); i++ {
+				l := lines[i]
+				if ind := leadingSpaces(l); ind < 4 && strings.HasPrefix(l[ind:], fence) && strings.Trim(l[ind:], fence[:1]+" ") == "" {
+					i++
+					break
+				}
+				code = append(code, l[min(indent, leadingSpaces(l)):])
+			}
+			if len(code) > 0 {
+				block.text = strings.Join(code, "\n") + "\n"
+			}
+			add(block)
+
+		case atxHeadingLevel(trimmed) > 0:
+			flush()
+			level := atxHeadingLevel(trimmed)
+			text := strings.TrimSpace(trimmed[level:])
+			if closing := strings.TrimRight(text, "#"); closing == "" || strings.HasSuffix(closing, " ") {
+				text = strings.TrimSpace(closing)
+			}
+			add(&markdownBlock{kind: markdownHeading, level: level, text: text})
+			i++
+
+		case len(para) > 0 && isSetextUnderline(trimmed):
+			level := 1
+			if trimmed[0] == '-' {
+				level = 2
+			}
+			text := p.definitions(strings.Join(para, "\n"))
+			para = nil
+			if strings.TrimSpace(text) != "" {
+				add(&markdownBlock{kind: markdownHeading, level: level, text: strings.TrimSpace(text)})
+			} else {
+				para = []string{trimmed}
+			}
+			i++
+
+		case isThematicBreak(trimmed):
+			flush()
+			add(&markdownBlock{kind: markdownThematicBreak})
+			i++
+
+		case trimmed[0] == '>':
+			flush()
+			var quoted []string
+			for ; i < len(lines); i++ {
+				l := lines[i]
+				ind := leadingSpaces(l)
+				if ind < 4 && strings.HasPrefix(l[ind:], ">") {
+					l = l[ind+1:]
+					if strings.HasPrefix(l, " ") {
+						l = l[1:]
+					}
+					quoted = append(quoted, l)
+					continue
+				}
+				// Lazy continuation lines continue a quoted paragraph.
+				if isBlankLine(l) || len(quoted) == 0 || isBlankLine(quoted[len(quoted)-1]) || startsMarkdownBlock(l) {
+					break
+				}
+				quoted = append(quoted, l)
+			}
+			add(&markdownBlock{kind: markdownQuote, children: p.blocks(quoted)})
+
+		case len(para) == 0 && i+1 < len(lines) && strings.Contains(trimmed, "|") && tableAlignment(lines[i+1]) != nil &&
+			len(tableAlignment(lines[i+1])) == len(splitTableRow(trimmed)):
+			block := &markdownBlock{kind: markdownTable, align: tableAlignment(lines[i+1])}
+			block.rows = append(block.rows, splitTableRow(trimmed))
+			for i += 2; i < len(lines) && !isBlankLine(lines[i]) && !startsMarkdownBlock(lines[i]); i++ {
+				block.rows = append(block.rows, splitTableRow(strings.TrimSpace(lines[i])))
+			}
+			add(block)
+
+		default:
+			if marker, ok := parseListMarker(line); ok && (len(para) == 0 || marker.interruptsParagraph) {
+				flush()
+				var list *markdownBlock
+				list, i = p.list(lines, i, marker)
+				add(list)
+				continue
+			}
+			para = append(para, trimmed)
+			i++
+		}
+	}
+	flush()
+	return blocks
+}
+
+// list parses the list starting at lines[i] and returns it with the index
+// of the first line after it.
+func (p *markdownParser) list(lines []string, i int, first listMarker) (*markdownBlock, int) {
+	list := &markdownBlock{kind: markdownList, ordered: first.ordered, start: first.number, tight: true}
+	for i < len(lines) {
+		marker, ok := parseListMarker(lines[i])
+		if !ok || marker.ordered != first.ordered || marker.delimiter != first.delimiter {
+			break
+		}
+
+		content := []string{lines[i][min(marker.contentIndent, len(lines[i])):]}
+		blank := false
+		for i++; i < len(lines); i++ {
+			l := lines[i]
+			switch {
+			case isBlankLine(l):
+				content = append(content, "")
+				blank = true
+				continue
+			case leadingSpaces(l) >= marker.contentIndent:
+				content = append(content, l[marker.contentIndent:])
+				blank = false
+				continue
+			case !blank && !startsMarkdownBlock(l) && !isListItem(l):
+				// Lazy continuation of the last paragraph of the item.
+				content = append(content, l)
+				continue
+			}
+			break
+		}
+
+		trailing := 0
+		for len(content) > 0 && content[len(content)-1] == "" {
+			content = content[:len(content)-1]
+			trailing++
+		}
+		item := &markdownBlock{kind: markdownItem, children: p.blocks(content)}
+		for _, child := range item.children[min(1, len(item.children)):] {
+			if child.blankBefore {
+				list.tight = false
+			}
+		}
+		list.children = append(list.children, item)
+
+		if trailing > 0 {
+			if next, ok := parseListMarker(lineAt(lines, i)); ok && next.ordered == first.ordered && next.delimiter == first.delimiter {
+				list.tight = false
+			}
+		}
+	}
+	// Trailing blank lines of the last item end the list and are left to
+	// the caller.
+	for i > 0 && isBlankLine(lines[i-1]) {
+		i--
+	}
+	return list, i
+}
+
+// definitions removes the link reference definitions at the start of a
+// paragraph and returns the rest.
+func (p *markdownParser) definitions(text string) string {
+	for strings.HasPrefix(text, "[") {
+		end := strings.Index(text, "]:")
+		if end < 0 {
+			return text
+		}
+		label := normalizeLabel(text[1:end])
+		if label == "" || strings.ContainsAny(text[1:end], "[]") {
+			return text
+		}
+		rest := strings.TrimLeft(text[end+2:], " \n")
+		url, n, ok := scanLinkDestination(rest)
+		if !ok || url == "" && !strings.HasPrefix(rest, "<>") {
+			return text
+		}
+		rest = rest[n:]
+
+		// The optional title follows on the same or the next line.
+		var title string
+		trimmed := strings.TrimLeft(rest, " \n")
+		if t, m, ok := scanLinkTitle(trimmed); ok && len(trimmed) < len(rest) && isBlankLine(firstLine(trimmed[m:])) {
+			title, rest = t, trimmed[m:]
+		}
+		if !isBlankLine(firstLine(rest)) {
+			return text
+		}
+		if _, exists := p.refs[label]; !exists {
+			p.refs[label] = markdownLink{url: url, title: title}
+		}
+		text = rest[min(len(firstLine(rest))+1, len(rest)):]
+	}
+	return text
+}
+
+type listMarker struct {
+	ordered             bool
+	delimiter           byte // '-', '+', '*', '.' or ')'
+	number              int
+	contentIndent       int // column of the item content
+	interruptsParagraph bool
+}
+
+// parseListMarker parses the list item marker at the start of line.
+func parseListMarker(line string) (listMarker, bool) {
+	indent := leadingSpaces(line)
+	if indent >= 4 || indent == len(line) {
+		return listMarker{}, false
+	}
+	var m listMarker
+	i := indent
+	switch c := line[i]; {
+	case c == '-' || c == '+' || c == '*':
+		m.delimiter = c
+		i++
+	case c >= '0' && c <= '9':
+		for i < len(line) && i-indent < 9 && line[i] >= '0' && line[i] <= '9' {
+			i++
+		}
+		if i >= len(line) || line[i] != '.' && line[i] != ')' {
+			return listMarker{}, false
+		}
+		m.ordered = true
+		m.number, _ = strconv.Atoi(line[indent:i])
+		m.delimiter = line[i]
+		i++
+	default:
+		return listMarker{}, false
+	}
+
+	spaces := leadingSpaces(line[i:])
+	empty := i+spaces == len(line)
+	switch {
+	case spaces == 0 && !empty:
+		return listMarker{}, false
+	case empty || spaces > 4:
+		// Content indented by more than four spaces is a code block.
+		m.contentIndent = i + 1
+	default:
+		m.contentIndent = i + spaces
+	}
+	m.interruptsParagraph = !empty && (!m.ordered || m.number == 1)
+	return m, true
+}
+
+// startsMarkdownBlock reports whether line starts a block that interrupts
+// a paragraph.
+func startsMarkdownBlock(line string) bool {
+	indent := leadingSpaces(line)
+	if indent >= 4 {
+		return false
+	}
+	trimmed := line[indent:]
+	if marker, ok := parseListMarker(line); ok && marker.interruptsParagraph {
+		return true
+	}
+	return isFence(trimmed) || atxHeadingLevel(trimmed) > 0 || isThematicBreak(trimmed) || strings.HasPrefix(trimmed, ">")
+}
+
+func isFence(s string) bool {
+	if !strings.HasPrefix(s, "```") && !strings.HasPrefix(s, "~~~") {
+		return false
+	}
+	fence := len(s) - len(strings.TrimLeft(s, s[:1]))
+	return s[0] == '~' || !strings.Contains(s[fence:], "`")
+}
+
+func atxHeadingLevel(s string) int {
+	level := len(s) - len(strings.TrimLeft(s, "#"))
+	if level < 1 || level > 6 || level < len(s) && s[level] != ' ' {
+		return 0
+	}
+	return level
+}
+
+func isSetextUnderline(s string) bool {
+	s = strings.TrimRight(s, " ")
+	return s != "" && (strings.Trim(s, "=") == "" || strings.Trim(s, "-") == "")
+}
+
+func isThematicBreak(s string) bool {
+	if s == "" || s[0] != '-' && s[0] != '*' && s[0] != '_' {
+		return false
+	}
+	n := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case s[0]:
+			n++
+		case ' ':
+		default:
+			return false
+		}
+	}
+	return n >= 3
+}
+
+// tableAlignment parses the delimiter row of a table. It returns nil if
+// line is not a delimiter row.
+func tableAlignment(line string) []string {
+	line = strings.TrimSpace(line)
+	if !strings.Contains(line, "-") || strings.Trim(line, "|:- ") != "" || !strings.Contains(line, "|") && !strings.Contains(line, ":") {
+		return nil
+	}
+	var align []string
+	for _, cell := range splitTableRow(line) {
+		if strings.Trim(cell, ":") == "" || strings.Trim(cell, "-:") != "" {
+			return nil
+		}
+		switch left, right := cell[0] == ':', cell[len(cell)-1] == ':'; {
+		case left && right:
+			align = append(align, "center")
+		case right:
+			align = append(align, "right")
+		case left:
+			align = append(align, "left")
+		default:
+			align = append(align, "")
+		}
+	}
+	return align
+}
+
+// splitTableRow splits a table row into cells at unescaped pipes.
+func splitTableRow(row string) []string {
+	row = strings.TrimSpace(row)
+	row = strings.TrimPrefix(row, "|")
+	if strings.HasSuffix(row, "|") && !strings.HasSuffix(row, `\|`) {
+		row = row[:len(row)-1]
+	}
+	var cells []string
+	start := 0
+	for i := 0; i < len(row); i++ {
+		switch row[i] {
+		case '\\':
+			i++
+		case '|':
+			cells = append(cells, strings.TrimSpace(row[start:i]))
+			start = i + 1
+		}
+	}
+	return append(cells, strings.TrimSpace(row[start:]))
+}
+
+func leadingSpaces(s string) int {
+	n := 0
+	for n < len(s) && s[n] == ' ' {
+		n++
+	}
+	return n
+}
+
+func isBlankLine(s string) bool {
+	return strings.TrimSpace(s) == ""
+}
+
+func firstLine(s string) string {
+	if end := strings.IndexByte(s, '\n'); end >= 0 {
+		return s[:end]
+	}
+	return s
+}
+
+func isListItem(line string) bool {
+	_, ok := parseListMarker(line)
+	return ok
+}
+
+func lineAt(lines []string, i int) string {
+	if i < len(lines) {
+		return lines[i]
+	}
+	return ""
+}
+
+// expandTabs replaces the tabs of the indentation of line with spaces,
+// using tab stops of four columns.
+func expandTabs(line string) string {
+	if !strings.HasPrefix(strings.TrimLeft(line, " "), "\t") {
+		return line
+	}
+	var b strings.Builder
+	column := 0
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case ' ':
+			b.WriteByte(' ')
+			column++
+		case '\t':
+			n := 4 - column%4
+			b.WriteString(strings.Repeat(" ", n))
+			column += n
+		default:
+			b.WriteString(line[i:])
+			return b.String()
+		}
+	}
+	return b.String()
+}
+
+// normalizeLabel normalizes a link label for matching: case-insensitive
+// with collapsed whitespace.
+func normalizeLabel(label string) string {
+	return strings.ToLower(strings.Join(strings.Fields(label), " "))
+}
+
+// render writes the HTML of blocks. Paragraphs of tight lists are written
+// without <p> elements.
+func (p *markdownParser) render(b *strings.Builder, blocks []*markdownBlock, tight bool) {
+	for _, block := range blocks {
+		switch block.kind {
+		case markdownParagraph:
+			text := p.inline(strings.TrimSpace(block.text))
+			if tight {
+				b.WriteString(text + "\n")
+			} else {
+				b.WriteString("<p>" + text + "</p>\n")
+			}
+		case markdownHeading:
+			tag := "h" + strconv.Itoa(block.level)
+			b.WriteString("<" + tag + ">" + p.inline(block.text) + "</" + tag + ">\n")
+		case markdownThematicBreak:
+			b.WriteString("<hr>\n")
+		case markdownCode:
+			b.WriteString("<pre><code")
+			if block.lang != "" {
+				b.WriteString(` class="language-` + markdownEscaper.Replace(block.lang) + `"`)
+			}
+			b.WriteString(">" + markdownEscaper.Replace(block.text) + "</code></pre>\n")
+		case markdownQuote:
+			b.WriteString("<blockquote>\n")
+			p.render(b, block.children, false)
+			b.WriteString("</blockquote>\n")
+		case markdownList:
+			p.renderList(b, block)
+		case markdownTable:
+			p.renderTable(b, block)
+		}
+	}
+}
+
+func (p *markdownParser) renderList(b *strings.Builder, list *markdownBlock) {
+	tag := "ul"
+	if list.ordered {
+		tag = "ol"
+	}
+	b.WriteString("<" + tag)
+	if list.ordered && list.start != 1 {
+		b.WriteString(` start="` + strconv.Itoa(list.start) + `"`)
+	}
+	b.WriteString(">\n")
+	for _, item := range list.children {
+		var content strings.Builder
+		p.render(&content, item.children, list.tight)
+		text := content.String()
+		if len(item.children) > 0 && (!list.tight || item.children[0].kind != markdownParagraph) {
+			text = "\n" + text
+		}
+		if len(item.children) > 0 && list.tight && item.children[len(item.children)-1].kind == markdownParagraph {
+			text = strings.TrimSuffix(text, "\n")
+		}
+		b.WriteString("<li>" + text + "</li>\n")
+	}
+	b.WriteString("</" + tag + ">\n")
+}
+
+func (p *markdownParser) renderTable(b *strings.Builder, table *markdownBlock) {
+	b.WriteString("<table>\n<thead>\n")
+	for r, row := range table.rows {
+		if r == 1 {
+			b.WriteString("<tbody>\n")
+		}
+		tag := "td"
+		if r == 0 {
+			tag = "th"
+		}
+		b.WriteString("<tr>")
+		for c, align := range table.align {
+			b.WriteString("<" + tag)
+			if align != "" {
+				b.WriteString(` align="` + align + `"`)
+			}
+			var cell string
+			if c < len(row) {
+				cell = p.inline(strings.ReplaceAll(row[c], `\|`, "|"))
+			}
+			b.WriteString(">" + cell + "</" + tag + ">")
+		}
+		b.WriteString("</tr>\n")
+		if r == 0 {
+			b.WriteString("</thead>\n")
+		}
+	}
+	if len(table.rows) > 1 {
+		b.WriteString("</tbody>\n")
+	}
+	b.WriteString("</table>\n")
+}
+
+var markdownEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;")
+
+// markdownPiece is a piece of rendered inline HTML, or a run of emphasis
+// delimiters.
+type markdownPiece struct {
+	html string
+
+	delimiter     byte // '*', '_' or '~' for delimiter runs
+	count, length int  // remaining and original length of the run
+	canOpen       bool
+	canClose      bool
+	opening       []string // tags opened after the remaining delimiters
+	closing       []string // tags closed before the remaining delimiters
+}
+
+// inline renders the inline content of a block.
+func (p *markdownParser) inline(s string) string {
+	var (
+		pieces []*markdownPiece
+		text   strings.Builder
+	)
+	flush := func() {
+		if text.Len() > 0 {
+			pieces = append(pieces, &markdownPiece{html: markdownEscaper.Replace(text.String())})
+			text.Reset()
+		}
+	}
+	emit := func(html string) {
+		flush()
+		pieces = append(pieces, &markdownPiece{html: html})
+	}
+
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == '\\' && i+1 < len(s) && s[i+1] == '\n':
+			emit("<br>\n")
+			i += 2
+		case c == '\\' && i+1 < len(s) && isASCIIPunct(s[i+1]):
+			text.WriteByte(s[i+1])
+			i += 2
+
+		case c == '`':
+			run := len(s[i:]) - len(strings.TrimLeft(s[i:], "`"))
+			if end := closingBackticks(s[i+run:], run); end >= 0 {
+				code := strings.ReplaceAll(s[i+run:i+run+end], "\n", " ")
+				if len(code) > 2 && code[0] == ' ' && code[len(code)-1] == ' ' && strings.Trim(code, " ") != "" {
+					code = code[1 : len(code)-1]
+				}
+				emit("<code>" + markdownEscaper.Replace(code) + "</code>")
+				i += run + end + run
+				continue
+			}
+			text.WriteString(s[i : i+run])
+			i += run
+
+		case c == '*' || c == '_' || c == '~':
+			run := len(s[i:]) - len(strings.TrimLeft(s[i:], s[i:i+1]))
+			before, _ := utf8.DecodeLastRuneInString(s[:i])
+			after, _ := utf8.DecodeRuneInString(s[i+run:])
+			if i == 0 {
+				before = ' '
+			}
+			if i+run == len(s) {
+				after = ' '
+			}
+			left := !unicode.IsSpace(after) && (!isPunctRune(after) || unicode.IsSpace(before) || isPunctRune(before))
+			right := !unicode.IsSpace(before) && (!isPunctRune(before) || unicode.IsSpace(after) || isPunctRune(after))
+			piece := &markdownPiece{delimiter: c, count: run, length: run, canOpen: left, canClose: right}
+			if c == '_' {
+				piece.canOpen = left && (!right || isPunctRune(before))
+				piece.canClose = right && (!left || isPunctRune(after))
+			}
+			flush()
+			pieces = append(pieces, piece)
+			i += run
+
+		case c == '!' && strings.HasPrefix(s[i+1:], "["):
+			if html, n, ok := p.link(s[i+1:], true); ok {
+				emit(html)
+				i += 1 + n
+				continue
+			}
+			text.WriteByte(c)
+			i++
+		case c == '[':
+			if html, n, ok := p.link(s[i:], false); ok {
+				emit(html)
+				i += n
+				continue
+			}
+			text.WriteByte(c)
+			i++
+
+		case c == '<':
+			if html, n, ok := autolink(s[i:]); ok {
+				emit(html)
+				i += n
+				continue
+			}
+			text.WriteByte(c)
+			i++
+
+		case c == '&':
+			if end := strings.IndexByte(s[i:], ';'); end > 1 && end < 33 && strings.Trim(s[i+1:i+end], "#abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789") == "" {
+				if entity := html.UnescapeString(s[i : i+end+1]); entity != s[i:i+end+1] {
+					text.WriteString(entity)
+					i += end + 1
+					continue
+				}
+			}
+			text.WriteByte(c)
+			i++
+
+		case c == '\n':
+			pending := text.String()
+			trimmed := strings.TrimRight(pending, " ")
+			text.Reset()
+			text.WriteString(trimmed)
+			if len(pending)-len(trimmed) >= 2 {
+				emit("<br>\n")
+			} else {
+				text.WriteByte('\n')
+			}
+			i++
+			for i < len(s) && s[i] == ' ' {
+				i++
+			}
+
+		default:
+			text.WriteByte(c)
+			i++
+		}
+	}
+	flush()
+
+	processEmphasis(pieces)
+	var b strings.Builder
+	for _, piece := range pieces {
+		if piece.delimiter == 0 {
+			b.WriteString(piece.html)
+			continue
+		}
+		for _, tag := range piece.closing {
+			b.WriteString(tag)
+		}
+		b.WriteString(strings.Repeat(string(piece.delimiter), piece.count))
+		for _, tag := range piece.opening {
+			b.WriteString(tag)
+		}
+	}
+	return b.String()
+}
+
+// processEmphasis matches emphasis delimiter runs, following the
+// CommonMark algorithm.
+func processEmphasis(pieces []*markdownPiece) {
+	for c, closer := range pieces {
+		for closer.delimiter != 0 && closer.canClose && closer.count > 0 {
+			o := -1
+			for k := c - 1; k >= 0; k-- {
+				opener := pieces[k]
+				if opener.delimiter != closer.delimiter || !opener.canOpen || opener.count == 0 {
+					continue
+				}
+				if (opener.canClose || closer.canOpen) && (opener.length+closer.length)%3 == 0 && (opener.length%3 != 0 || closer.length%3 != 0) {
+					continue
+				}
+				if closer.delimiter == '~' && (opener.count < 2 || closer.count < 2) {
+					continue
+				}
+				o = k
+				break
+			}
+			if o < 0 {
+				break
+			}
+
+			opener := pieces[o]
+			n, tag := 1, "em"
+			switch {
+			case closer.delimiter == '~':
+				n, tag = 2, "del"
+			case opener.count >= 2 && closer.count >= 2:
+				n, tag = 2, "strong"
+			}
+			opener.count -= n
+			closer.count -= n
+			opener.opening = append([]string{"<" + tag + ">"}, opener.opening...)
+			closer.closing = append(closer.closing, "</"+tag+">")
+
+			// Delimiters between the opener and closer can no longer match.
+			for _, between := range pieces[o+1 : c] {
+				between.canOpen, between.canClose = false, false
+			}
+		}
+	}
+}
+
+// link renders the link or image starting at the "[" at the start of s. It
+// returns the HTML and the length of the link.
+func (p *markdownParser) link(s string, image bool) (string, int, bool) {
+	end := closingBracket(s)
+	if end < 0 {
+		return "", 0, false
+	}
+	label := s[1:end]
+	n := end + 1
+
+	var target markdownLink
+	switch rest := s[n:]; {
+	case strings.HasPrefix(rest, "("):
+		inner := strings.TrimLeft(rest[1:], " \n")
+		consumed := len(rest) - len(inner)
+		url, m, ok := scanLinkDestination(inner)
+		if !ok {
+			return "", 0, false
+		}
+		target.url = url
+		inner, consumed = inner[m:], consumed+m
+
+		trimmed := strings.TrimLeft(inner, " \n")
+		if title, m, ok := scanLinkTitle(trimmed); ok && len(trimmed) < len(inner) {
+			target.title = title
+			consumed += len(inner) - len(trimmed) + m
+			inner = trimmed[m:]
+		}
+		trimmed = strings.TrimLeft(inner, " \n")
+		consumed += len(inner) - len(trimmed)
+		if !strings.HasPrefix(trimmed, ")") {
+			return "", 0, false
+		}
+		n += consumed + 1
+
+	case strings.HasPrefix(rest, "["):
+		refEnd := strings.IndexByte(rest, ']')
+		if refEnd < 0 {
+			return "", 0, false
+		}
+		ref := rest[1:refEnd]
+		if ref == "" {
+			ref = label
+		}
+		link, ok := p.refs[normalizeLabel(ref)]
+		if !ok {
+			return "", 0, false
+		}
+		target = link
+		n += refEnd + 1
+
+	default:
+		link, ok := p.refs[normalizeLabel(label)]
+		if !ok {
+			return "", 0, false
+		}
+		target = link
+	}
+
+	content := p.inline(label)
+	if image {
+		alt := captureText(parseHTML(content))
+		if !safeMarkdownURL(target.url, true) {
+			return markdownEscaper.Replace(alt), n, true
+		}
+		html := `<img src="` + markdownEscaper.Replace(target.url) + `" alt="` + markdownEscaper.Replace(alt) + `"`
+		if target.title != "" {
+			html += ` title="` + markdownEscaper.Replace(target.title) + `"`
+		}
+		return html + ">", n, true
+	}
+	if strings.Contains(content, "<a ") {
+		// Links cannot contain links; the brackets are text.
+		return "[" + content + "]", end + 1, true
+	}
+	if !safeMarkdownURL(target.url, false) {
+		return content, n, true
+	}
+	html := `<a href="` + markdownEscaper.Replace(target.url) + `"`
+	if target.title != "" {
+		html += ` title="` + markdownEscaper.Replace(target.title) + `"`
+	}
+	return html + ">" + content + "</a>", n, true
+}
+
+// autolink renders the autolink, e.g. <https://example.com>, at the start
+// of s.
+func autolink(s string) (string, int, bool) {
+	end := strings.IndexAny(s[1:], "<> \n")
+	if end < 0 || s[1+end] != '>' {
+		return "", 0, false
+	}
+	target := s[1 : 1+end]
+	href := target
+	if colon := strings.IndexByte(target, ':'); colon >= 2 && colon <= 32 && isURLScheme(target[:colon]) {
+		if !safeMarkdownURL(target, false) {
+			return "", 0, false
+		}
+	} else if at := strings.IndexByte(target, '@'); at > 0 && at < len(target)-1 && strings.Contains(target[at:], ".") {
+		href = "mailto:" + target
+	} else {
+		return "", 0, false
+	}
+	return `<a href="` + markdownEscaper.Replace(href) + `">` + markdownEscaper.Replace(target) + "</a>", end + 2, true
+}
+
+// safeMarkdownURL reports whether a link or image URL may be rendered.
+// URLs without a scheme are relative and allowed.
+func safeMarkdownURL(url string, image bool) bool {
+	cleaned := strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) || unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, url)
+	colon := strings.IndexByte(cleaned, ':')
+	if colon < 0 || strings.ContainsAny(cleaned[:colon], "/?#") {
+		return true
+	}
+	switch strings.ToLower(cleaned[:colon]) {
+	case "http", "https":
+		return true
+	case "mailto", "tel":
+		return !image
+	case "cid":
+		return image
+	}
+	return false
+}
+
+// scanLinkDestination scans the link destination at the start of s.
+func scanLinkDestination(s string) (string, int, bool) {
+	if strings.HasPrefix(s, "<") {
+		end := strings.IndexAny(s[1:], "<>\n")
+		if end < 0 || s[1+end] != '>' {
+			return "", 0, false
+		}
+		return unescapeMarkdown(s[1 : 1+end]), end + 2, true
+	}
+	depth := 0
+	i := 0
+	for ; i < len(s); i++ {
+		c := s[i]
+		if c == '\\' && i+1 < len(s) && isASCIIPunct(s[i+1]) {
+			i++
+			continue
+		}
+		if c == ' ' || c == '\n' || c < 0x20 {
+			break
+		}
+		if c == '(' {
+			depth++
+		} else if c == ')' {
+			if depth == 0 {
+				break
+			}
+			depth--
+		}
+	}
+	if depth != 0 {
+		return "", 0, false
+	}
+	return unescapeMarkdown(s[:i]), i, true
+}
+
+// scanLinkTitle scans the quoted link title at the start of s.
+func scanLinkTitle(s string) (string, int, bool) {
+	if s == "" {
+		return "", 0, false
+	}
+	closing := map[byte]byte{'"': '"', '\'': '\'', '(': ')'}[s[0]]
+	if closing == 0 {
+		return "", 0, false
+	}
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case closing:
+			return unescapeMarkdown(s[1:i]), i + 1, true
+		}
+	}
+	return "", 0, false
+}
+
+// unescapeMarkdown resolves backslash escapes and entities.
+func unescapeMarkdown(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) && isASCIIPunct(s[i+1]) {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+	return html.UnescapeString(b.String())
+}
+
+// closingBracket returns the index of the "]" matching the "[" at the start
+// of s, or -1.
+func closingBracket(s string) int {
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '`':
+			run := len(s[i:]) - len(strings.TrimLeft(s[i:], "`"))
+			if end := closingBackticks(s[i+run:], run); end >= 0 {
+				i += run + end + run - 1
+			} else {
+				i += run - 1
+			}
+		case '[':
+			depth++
+		case ']':
+			if depth--; depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// closingBackticks returns the index of the next run of exactly n
+// backticks in s, or -1.
+func closingBackticks(s string, n int) int {
+	for i := 0; i < len(s); {
+		if s[i] != '`' {
+			i++
+			continue
+		}
+		run := len(s[i:]) - len(strings.TrimLeft(s[i:], "`"))
+		if run == n {
+			return i
+		}
+		i += run
+	}
+	return -1
+}
+
+func isURLScheme(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !isASCIILetter(c) && (i == 0 || c != '+' && c != '-' && c != '.' && (c < '0' || c > '9')) {
+			return false
+		}
+	}
+	return s != ""
+}
+
+func isASCIIPunct(c byte) bool {
+	return c >= '!' && c <= '/' || c >= ':' && c <= '@' || c >= '[' && c <= '`' || c >= '{' && c <= '~'
+}
+
+func isPunctRune(r rune) bool {
+	return unicode.IsPunct(r) || unicode.IsSymbol(r)
+}
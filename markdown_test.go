@@ -0,0 +1,126 @@
+package lettermint
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	htmltemplate "html/template"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMarkdownToHTML(t *testing.T) {
+	tests := []struct {
+		name     string
+		markdown string
+		want     string
+	}{
+		{
+			name:     "headings and paragraphs",
+			markdown: "# Receipt #\n\nThanks for\nyour order.  \nIt ships today.\n\nItems\n-----\n\n***",
+			want:     "<h1>Receipt</h1>\n<p>Thanks for\nyour order.<br>\nIt ships today.</p>\n<h2>Items</h2>\n<hr>",
+		},
+		{
+			name:     "emphasis",
+			markdown: "*em* **strong** ***both*** _em_ __strong__ ~~gone~~ snake_case_name 2 * 3 * 4 *a **b** c*",
+			want:     "<p><em>em</em> <strong>strong</strong> <em><strong>both</strong></em> <em>em</em> <strong>strong</strong> <del>gone</del> snake_case_name 2 * 3 * 4 <em>a <strong>b</strong> c</em></p>",
+		},
+		{
+			name:     "lists",
+			markdown: "- One\n- Two\n  * Nested\n- Three\n\n3. First\n\n   More\n4. Second",
+			want: "<ul>\n<li>One</li>\n<li>Two\n<ul>\n<li>Nested</li>\n</ul>\n</li>\n<li>Three</li>\n</ul>\n" +
+				"<ol start=\"3\">\n<li>\n<p>First</p>\n<p>More</p>\n</li>\n<li>\n<p>Second</p>\n</li>\n</ol>",
+		},
+		{
+			name:     "quotes and code",
+			markdown: "> Quoted\nlazily\n> > Nested\n\n```go\nif a < b {\n}\n```\n\n    indented",
+			want: "<blockquote>\n<p>Quoted\nlazily</p>\n<blockquote>\n<p>Nested</p>\n</blockquote>\n</blockquote>\n" +
+				"<pre><code class=\"language-go\">if a &lt; b {\n}\n</code></pre>\n<pre><code>indented\n</code></pre>",
+		},
+		{
+			name: "links and images",
+			markdown: "[Order](https://example.com/orders/1 \"View\") [docs] [Help][support] ![Logo](cid:logo) " +
+				"<https://example.com> <help@example.com> `[not](a link)`\n\n" +
+				"[docs]: https://example.com/docs\n[Support]: <https://example.com/help> 'Help center'",
+			want: "<p><a href=\"https://example.com/orders/1\" title=\"View\">Order</a> <a href=\"https://example.com/docs\">docs</a> " +
+				"<a href=\"https://example.com/help\" title=\"Help center\">Help</a> <img src=\"cid:logo\" alt=\"Logo\"> " +
+				"<a href=\"https://example.com\">https://example.com</a> <a href=\"mailto:help@example.com\">help@example.com</a> <code>[not](a link)</code></p>",
+		},
+		{
+			name:     "nested links",
+			markdown: "[[a](u)](v) [![Logo](logo.png)](https://example.com)",
+			want:     "<p>[<a href=\"u\">a</a>](v) <a href=\"https://example.com\"><img src=\"logo.png\" alt=\"Logo\"></a></p>",
+		},
+		{
+			name:     "tables",
+			markdown: "| Item | Qty |\n|:-----|----:|\n| Mug | 2 |\n| A \\| B | 1 |",
+			want: "<table>\n<thead>\n<tr><th align=\"left\">Item</th><th align=\"right\">Qty</th></tr>\n</thead>\n<tbody>\n" +
+				"<tr><td align=\"left\">Mug</td><td align=\"right\">2</td></tr>\n<tr><td align=\"left\">A | B</td><td align=\"right\">1</td></tr>\n</tbody>\n</table>",
+		},
+		{
+			name:     "escapes and entities",
+			markdown: "\\*not em\\* &copy; AT&T &bogus;",
+			want:     "<p>*not em* © AT&amp;T &amp;bogus;</p>",
+		},
+		{
+			name: "sanitized",
+			markdown: "<script>alert(1)</script><img src=x onerror=alert(1)>\n\n" +
+				"[click](javascript:alert(1)) [or](JavaScript:alert(1)) ![pixel](data:image/gif;base64,R0lG) <javascript:alert(1)>",
+			want: "<p>&lt;script&gt;alert(1)&lt;/script&gt;&lt;img src=x onerror=alert(1)&gt;</p>\n" +
+				"<p>click or pixel &lt;javascript:alert(1)&gt;</p>",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MarkdownToHTML(tt.markdown); got != tt.want {
+				t.Errorf("MarkdownToHTML() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEmailBuilder_Markdown(t *testing.T) {
+	var got emailPayload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		_, _ = w.Write([]byte(`{"message_id":"msg_1","status":"pending"}`))
+	}))
+	defer server.Close()
+
+	client, _ := New("test-token", WithBaseURL(server.URL))
+	email := func() *EmailBuilder {
+		return client.Email(context.Background()).
+			From("sender@example.com").
+			To("recipient@example.com").
+			Subject("Deploy finished")
+	}
+	source := "# Deploy finished\n\nVersion **1.2** is [live](https://example.com)."
+
+	if _, err := email().Markdown(source).Send(); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	wantHTML := "<h1>Deploy finished</h1>\n<p>Version <strong>1.2</strong> is <a href=\"https://example.com\">live</a>.</p>"
+	wantText := "Deploy finished\n===============\n\nVersion 1.2 is live (https://example.com)."
+	if got.HTML != wantHTML || got.Text != wantText {
+		t.Errorf("HTML, Text = %q, %q, want %q, %q", got.HTML, got.Text, wantHTML, wantText)
+	}
+
+	layout := htmltemplate.Must(htmltemplate.New("layout").Parse(`<html><body>{{.Content}}<p>{{.Data}}</p></body></html>`))
+	if _, err := email().Markdown(source, MarkdownLayout(layout, "Acme & Co")).Send(); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if want := "<html><body>" + wantHTML + "<p>Acme &amp; Co</p></body></html>"; got.HTML != want {
+		t.Errorf("HTML = %q, want %q", got.HTML, want)
+	}
+	if got.Text != wantText {
+		t.Errorf("Text = %q, want the text of the Markdown without the layout", got.Text)
+	}
+
+	broken := htmltemplate.Must(htmltemplate.New("broken").Parse(`{{.Content.Missing}}`))
+	_, err := email().Markdown(source, MarkdownLayout(broken, nil)).Send()
+	var templateErr *TemplateError
+	if !errors.Is(err, ErrInvalidRequest) || !errors.As(err, &templateErr) || templateErr.Template != "broken" {
+		t.Errorf("Send() error = %v, want a *TemplateError for the layout", err)
+	}
+}
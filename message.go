@@ -0,0 +1,263 @@
+package lettermint
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+)
+
+// Message is an email that is ready to be sent with Client.Send.
+//
+// Unlike an EmailBuilder, a Message is not tied to a context or a send: it
+// can be built once with EmailBuilder.Build, logged, serialized, and sent or
+// retried with a fresh context. Client.Send does not modify the message.
+// Copies of a Message share their slices and maps; use Clone to get an
+// independent copy before changing one. Attachments added with
+// AttachReader, AttachFile or AttachFS are read from their source whenever
+// the message is sent or marshaled, and copies, clones and the builder share
+// that source.
+//
+// A Message marshals to JSON with the content of every attachment, so it
+// can be queued and sent by another process.
+type Message struct {
+	From    string   `json:"from"`
+	To      []string `json:"to"`
+	CC      []string `json:"cc,omitempty"`
+	BCC     []string `json:"bcc,omitempty"`
+	ReplyTo []string `json:"reply_to,omitempty"`
+	Subject string   `json:"subject"`
+	HTML    string   `json:"html,omitempty"`
+	Text    string   `json:"text,omitempty"`
+
+	Headers     map[string]string `json:"headers,omitempty"`
+	Attachments []Attachment      `json:"attachments,omitempty"`
+	Route       string            `json:"route,omitempty"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+	Tag         string            `json:"tag,omitempty"`
+
+	// IdempotencyKey is sent as the Idempotency-Key header, so that sending
+	// the message again does not deliver it twice.
+	IdempotencyKey string `json:"idempotency_key,omitempty"`
+}
+
+// Clone returns a copy of the message that shares no slices or maps with
+// it. The sources of streamed attachments are shared; see Message.
+func (m Message) Clone() Message {
+	clone := m
+	clone.To = slices.Clone(m.To)
+	clone.CC = slices.Clone(m.CC)
+	clone.BCC = slices.Clone(m.BCC)
+	clone.ReplyTo = slices.Clone(m.ReplyTo)
+	clone.Headers = maps.Clone(m.Headers)
+	clone.Attachments = slices.Clone(m.Attachments)
+	clone.Metadata = maps.Clone(m.Metadata)
+	return clone
+}
+
+// MarshalJSON encodes the message. Attachments added with AttachReader,
+// AttachFile or AttachFS are read and base64-encoded.
+func (m Message) MarshalJSON() ([]byte, error) {
+	type message Message
+	envelope := m
+	envelope.Attachments = nil
+	head, err := json.Marshal((*message)(&envelope))
+	if err != nil || len(m.Attachments) == 0 {
+		return head, err
+	}
+
+	var buf bytes.Buffer
+	if err := writeJSONWithAttachments(&buf, head, m.Attachments); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Build returns the composed email as a Message, without sending it.
+//
+// Build applies InlineCSS and TextFromHTML and validates the message. If
+// the client generates idempotency keys (see WithIdempotencyKeys) and no key
+// is set, Build assigns one, so that every send of the message uses the
+// same key. Errors wrap ErrInvalidRequest.
+//
+// The builder is not reset and can be changed and built again; the message
+// shares no slices or maps with it. Streamed attachments share their source
+// with the builder; see Message.
+//
+// Example:
+//
+//	msg, err := client.Email(ctx).
+//	    From("sender@example.com").
+//	    To("recipient@example.com").
+//	    Subject("Hello").
+//	    HTML("<p>World</p>").
+//	    Build()
+//	if err != nil {
+//	    return err
+//	}
+//	queue <- msg // sent by a worker with client.Send(workerCtx, msg)
+func (b *EmailBuilder) Build() (Message, error) {
+	if b.err != nil {
+		return Message{}, fmt.Errorf("%w: %w", ErrInvalidRequest, b.err)
+	}
+
+	msg := b.payload.message().Clone()
+	msg.IdempotencyKey = b.idempotencyKey
+	if b.inlineCSS != nil && msg.HTML != "" {
+		msg.HTML = b.inlineHTMLCSS(msg.HTML)
+	}
+	if (b.textFromHTML || b.client.textFromHTML) && msg.Text == "" && msg.HTML != "" {
+		msg.Text = HTMLToText(msg.HTML)
+	}
+	if err := msg.validate(); err != nil {
+		return Message{}, fmt.Errorf("%w: %v", ErrInvalidRequest, err)
+	}
+
+	header, err := b.client.idempotencyHeader(b.ctx, msg.IdempotencyKey, []SendMailRequest{msg.payload().sendMailRequest()})
+	if err != nil {
+		return Message{}, err
+	}
+	msg.IdempotencyKey = header.Get("Idempotency-Key")
+	return msg, nil
+}
+
+// Send sends a message via the Lettermint API.
+//
+// If the message has no idempotency key, one is generated when the client
+// is configured to (see WithIdempotencyKeys); CallIdempotencyKey overrides
+// the key of the message. Send validates the message and checks its
+// attachments against the client's AttachmentPolicy; these errors wrap
+// ErrInvalidRequest.
+//
+// Send is safe for concurrent use, including sending the same message from
+// several goroutines. Attachments added with AttachReader from a reader
+// that implements io.Seeker but not io.ReaderAt are read by one send at a
+// time.
+func (c *Client) Send(ctx context.Context, msg Message, opts ...CallOption) (*SendResponse, error) {
+	ctx, callHeader, cancel := applyCallOptions(ctx, opts)
+	defer cancel()
+
+	if err := msg.validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRequest, err)
+	}
+	payload := msg.payload()
+
+	key := callHeader.Get("Idempotency-Key")
+	if key == "" {
+		key = msg.IdempotencyKey
+	}
+	header, err := c.idempotencyHeader(ctx, key, []SendMailRequest{payload.sendMailRequest()})
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range callHeader {
+		header[key] = values
+	}
+	return c.sendPayload(ctx, payload, header)
+}
+
+// sendPayload checks the attachments of a validated payload and sends it.
+func (c *Client) sendPayload(ctx context.Context, payload *emailPayload, header http.Header) (*SendResponse, error) {
+	if c.attachmentPolicy != nil {
+		if err := c.attachmentPolicy.check(ctx, payload); err != nil {
+			var attachmentErr *AttachmentError
+			if errors.As(err, &attachmentErr) {
+				return nil, fmt.Errorf("%w: %w", ErrInvalidRequest, err)
+			}
+			return nil, err
+		}
+	}
+
+	body, err := payload.body()
+	if err != nil {
+		return nil, err
+	}
+	if stream, ok := body.(*emailBody); ok {
+		defer stream.wait()
+	}
+
+	var sendResp SendResponse
+	op := operation("Email.Send")
+	op.Tag = payload.Tag
+	err = c.observe(ctx, op, http.MethodPost, "/send", func(ctx context.Context) error {
+		resp, err := c.do(ctx, op, http.MethodPost, "/send", nil, body, header)
+		if err != nil {
+			return err
+		}
+		body, err := c.readResponse(ctx, resp)
+		if err != nil {
+			return err
+		}
+
+		if err := json.Unmarshal(body, &sendResp); err != nil {
+			return fmt.Errorf("failed to parse response: %w", err)
+		}
+		op.MessageID = sendResp.MessageID
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &sendResp, nil
+}
+
+// validate checks that all required fields are set.
+func (m *Message) validate() error {
+	if m.From == "" {
+		return fmt.Errorf("from address is required")
+	}
+	if len(m.To) == 0 {
+		return fmt.Errorf("at least one recipient is required")
+	}
+	if m.Subject == "" {
+		return fmt.Errorf("subject is required")
+	}
+	if m.HTML == "" && m.Text == "" {
+		return fmt.Errorf("either html or text body is required")
+	}
+	return nil
+}
+
+// payload returns the request payload of the message. It shares the
+// message's slices and maps, which the send does not modify.
+func (m *Message) payload() *emailPayload {
+	return &emailPayload{
+		From:        m.From,
+		To:          m.To,
+		Subject:     m.Subject,
+		HTML:        m.HTML,
+		Text:        m.Text,
+		CC:          m.CC,
+		BCC:         m.BCC,
+		ReplyTo:     m.ReplyTo,
+		Headers:     m.Headers,
+		Attachments: m.Attachments,
+		Route:       m.Route,
+		Metadata:    m.Metadata,
+		Tag:         m.Tag,
+	}
+}
+
+// message returns the payload as a Message sharing its slices and maps.
+func (p *emailPayload) message() Message {
+	return Message{
+		From:        p.From,
+		To:          p.To,
+		CC:          p.CC,
+		BCC:         p.BCC,
+		ReplyTo:     p.ReplyTo,
+		Subject:     p.Subject,
+		HTML:        p.HTML,
+		Text:        p.Text,
+		Headers:     p.Headers,
+		Attachments: p.Attachments,
+		Route:       p.Route,
+		Metadata:    p.Metadata,
+		Tag:         p.Tag,
+	}
+}
@@ -0,0 +1,294 @@
+package lettermint
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestEmailBuilder_Build(t *testing.T) {
+	client, _ := New("test-token")
+	builder := client.Email(context.Background()).
+		From("sender@example.com").
+		To("recipient@example.com").
+		Subject("Hello").
+		HTML("<p>Hi</p>").
+		MetadataValue("order_id", "42").
+		TextFromHTML()
+
+	msg, err := builder.Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	want := Message{
+		From:     "sender@example.com",
+		To:       []string{"recipient@example.com"},
+		CC:       []string{},
+		BCC:      []string{},
+		ReplyTo:  []string{},
+		Subject:  "Hello",
+		HTML:     "<p>Hi</p>",
+		Text:     "Hi",
+		Metadata: map[string]string{"order_id": "42"},
+	}
+	if !reflect.DeepEqual(msg, want) {
+		t.Errorf("Build() = %+v, want %+v", msg, want)
+	}
+
+	// The builder is not reset, and changing it does not change the message.
+	builder.To("other@example.com").MetadataValue("order_id", "43")
+	if len(msg.To) != 1 || msg.Metadata["order_id"] != "42" {
+		t.Errorf("message changed with the builder: %+v", msg)
+	}
+	again, err := builder.Build()
+	if err != nil || len(again.To) != 2 {
+		t.Errorf("second Build() = %+v, %v, want both recipients", again, err)
+	}
+
+	if _, err := client.Email(context.Background()).From("sender@example.com").Build(); !errors.Is(err, ErrInvalidRequest) {
+		t.Errorf("Build() error = %v, want ErrInvalidRequest", err)
+	}
+}
+
+func TestEmailBuilder_Build_IdempotencyKey(t *testing.T) {
+	calls := 0
+	client, _ := New("test-token", WithIdempotencyKeyFunc(func(ctx context.Context, emails []SendMailRequest) (string, error) {
+		calls++
+		return "key-" + emails[0].Metadata["order_id"], nil
+	}))
+	email := func() *EmailBuilder {
+		return client.Email(context.Background()).
+			From("sender@example.com").
+			To("recipient@example.com").
+			Subject("Hello").
+			Text("Hi").
+			MetadataValue("order_id", "42")
+	}
+
+	msg, err := email().Build()
+	if err != nil || msg.IdempotencyKey != "key-42" {
+		t.Errorf("Build() = %q, %v, want a generated key", msg.IdempotencyKey, err)
+	}
+	msg, err = email().IdempotencyKey("explicit").Build()
+	if err != nil || msg.IdempotencyKey != "explicit" {
+		t.Errorf("Build() = %q, %v, want the explicit key", msg.IdempotencyKey, err)
+	}
+	if calls != 1 {
+		t.Errorf("key function called %d times, want 1", calls)
+	}
+}
+
+func TestEmailBuilder_Send_KeepsInvalidEmail(t *testing.T) {
+	sends := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sends++
+		_ = json.NewEncoder(w).Encode(SendResponse{MessageID: "msg_1", Status: "pending"})
+	}))
+	defer server.Close()
+	client, _ := New("test-token", WithBaseURL(server.URL))
+
+	// A failed validation leaves the builder as it is, so it can be fixed.
+	builder := client.Email(context.Background()).From("sender@example.com").Subject("Hello").Text("Hi")
+	if _, err := builder.Send(); !errors.Is(err, ErrInvalidRequest) {
+		t.Fatalf("Send() error = %v, want ErrInvalidRequest", err)
+	}
+	msg, err := builder.To("recipient@example.com").Build()
+	if err != nil || msg.From != "sender@example.com" || msg.Subject != "Hello" {
+		t.Fatalf("Build() after a failed Send = %+v, %v, want the email kept", msg, err)
+	}
+
+	// So does an error recorded by a builder method.
+	builder = client.Email(context.Background()).
+		From("sender@example.com").
+		To("recipient@example.com").
+		Subject("Hello").
+		Text("Hi").
+		AttachFile("testdata/missing.pdf")
+	for i := 0; i < 2; i++ {
+		var attachmentErr *AttachmentError
+		if _, err := builder.Send(); !errors.As(err, &attachmentErr) {
+			t.Fatalf("Send() #%d error = %v, want *AttachmentError", i+1, err)
+		}
+	}
+	if sends != 0 {
+		t.Fatalf("sends = %d, want 0", sends)
+	}
+}
+
+func TestClient_Send(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		payloads []emailPayload
+		keys     []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload emailPayload
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("decode payload: %v", err)
+		}
+		mu.Lock()
+		payloads = append(payloads, payload)
+		keys = append(keys, r.Header.Get("Idempotency-Key"))
+		mu.Unlock()
+		_, _ = w.Write([]byte(`{"message_id":"msg_1","status":"pending"}`))
+	}))
+	defer server.Close()
+
+	client, _ := New("test-token", WithBaseURL(server.URL), WithIdempotencyKeys())
+
+	// The builder's context only applies to Build.
+	buildCtx, cancel := context.WithCancel(context.Background())
+	msg, err := client.Email(buildCtx).
+		From("sender@example.com").
+		To("recipient@example.com").
+		Subject("Report").
+		Text("Attached.").
+		AttachReader("report.txt", strings.NewReader("quarterly numbers")).
+		Build()
+	cancel()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := client.Send(context.Background(), msg)
+			if err != nil || resp.MessageID != "msg_1" {
+				t.Errorf("Send() = %+v, %v", resp, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(payloads) != 3 {
+		t.Fatalf("requests = %d, want 3", len(payloads))
+	}
+	for i, payload := range payloads {
+		if len(payload.Attachments) != 1 || payload.Attachments[0].Content != "cXVhcnRlcmx5IG51bWJlcnM=" {
+			t.Errorf("request %d attachments = %+v", i, payload.Attachments)
+		}
+		if keys[i] == "" || keys[i] != msg.IdempotencyKey {
+			t.Errorf("request %d Idempotency-Key = %q, want the message's key %q", i, keys[i], msg.IdempotencyKey)
+		}
+	}
+
+	if _, err := client.Send(context.Background(), msg, CallIdempotencyKey("override")); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if got := keys[len(keys)-1]; got != "override" {
+		t.Errorf("Idempotency-Key = %q, want the call option to override the message", got)
+	}
+
+	if _, err := client.Send(context.Background(), Message{From: "sender@example.com"}); !errors.Is(err, ErrInvalidRequest) {
+		t.Errorf("Send() error = %v, want ErrInvalidRequest for an invalid message", err)
+	}
+}
+
+func TestClient_Send_SeekerAttachment(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		contents []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload emailPayload
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		mu.Lock()
+		contents = append(contents, payload.Attachments[0].Content)
+		mu.Unlock()
+		_, _ = w.Write([]byte(`{"message_id":"msg_1","status":"pending"}`))
+	}))
+	defer server.Close()
+
+	client, _ := New("test-token", WithBaseURL(server.URL))
+	// Only an io.Seeker, so every send rewinds the same reader.
+	reader := struct{ io.ReadSeeker }{strings.NewReader(strings.Repeat("quarterly numbers ", 4096))}
+	builder := client.Email(context.Background()).
+		From("sender@example.com").
+		To("recipient@example.com").
+		Subject("Report").
+		Text("Attached.").
+		AttachReader("report.txt", reader)
+	msg, err := builder.Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(msg Message) {
+			defer wg.Done()
+			if _, err := client.Send(context.Background(), msg); err != nil {
+				t.Errorf("Send() error = %v", err)
+			}
+		}(msg.Clone())
+	}
+	wg.Wait()
+	if _, err := builder.Send(); err != nil {
+		t.Fatalf("builder Send() error = %v", err)
+	}
+
+	want := base64.StdEncoding.EncodeToString([]byte(strings.Repeat("quarterly numbers ", 4096)))
+	for i, content := range contents {
+		if content != want {
+			t.Errorf("request %d sent %d bytes of content, want the full attachment", i, len(content))
+		}
+	}
+}
+
+func TestMessage_Clone(t *testing.T) {
+	msg := Message{
+		To:          []string{"a@example.com"},
+		Headers:     map[string]string{"X-A": "1"},
+		Attachments: []Attachment{{Filename: "a.txt"}},
+	}
+	clone := msg.Clone()
+	clone.To[0] = "b@example.com"
+	clone.Headers["X-A"] = "2"
+	clone.Attachments[0].Filename = "b.txt"
+	if msg.To[0] != "a@example.com" || msg.Headers["X-A"] != "1" || msg.Attachments[0].Filename != "a.txt" {
+		t.Errorf("changing the clone changed the message: %+v", msg)
+	}
+}
+
+func TestMessage_JSON(t *testing.T) {
+	client, _ := New("test-token")
+	msg, err := client.Email(context.Background()).
+		From("sender@example.com").
+		To("recipient@example.com").
+		Subject("Report").
+		Text("Attached.").
+		AttachReader("report.pdf", strings.NewReader("numbers")).
+		IdempotencyKey("report-1").
+		Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	data, err := json.Marshal(msg)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	var decoded Message
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	want := Attachment{Filename: "report.pdf", Content: "bnVtYmVycw==", ContentType: "application/pdf"}
+	if len(decoded.Attachments) != 1 || decoded.Attachments[0] != want {
+		t.Errorf("attachments = %+v, want %+v", decoded.Attachments, want)
+	}
+	if decoded.IdempotencyKey != "report-1" || decoded.Subject != "Report" {
+		t.Errorf("decoded = %+v", decoded)
+	}
+}
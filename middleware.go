@@ -0,0 +1,66 @@
+package lettermint
+
+import "net/http"
+
+// Middleware wraps the transport of every request made by the client.
+//
+// A middleware receives the next http.RoundTripper in the chain and returns
+// a RoundTripper that typically inspects or modifies the request before
+// calling next. Requests are created for each attempt, so a middleware may
+// modify the request it receives. The chain is built once, when the client
+// is created, so the RoundTripper a middleware returns is shared by all
+// requests and must be safe for concurrent use.
+//
+// Example:
+//
+//	audit := func(next http.RoundTripper) http.RoundTripper {
+//	    return lettermint.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+//	        req.Header.Set("X-Request-Source", "billing-service")
+//	        return next.RoundTrip(req)
+//	    })
+//	}
+//
+//	client, err := lettermint.New("your-api-token", lettermint.WithMiddleware(audit))
+type Middleware func(next http.RoundTripper) http.RoundTripper
+
+// RoundTripperFunc is an adapter to allow the use of ordinary functions as
+// an http.RoundTripper.
+type RoundTripperFunc func(*http.Request) (*http.Response, error)
+
+// RoundTrip calls f(req).
+func (f RoundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// send performs a single HTTP round trip through the configured middleware.
+func (c *Client) send(req *http.Request) (*http.Response, error) {
+	if c.middlewareClient != nil {
+		return c.middlewareClient.Do(req)
+	}
+	return c.httpClient.Do(req)
+}
+
+// wrapMiddleware returns a copy of the configured HTTP client whose
+// transport is wrapped by the middleware, or nil without middleware. It is
+// called once when the client is created, so every middleware is
+// constructed once and keeps its state across requests.
+//
+// The middleware wraps the transport of the configured HTTP client, so
+// settings such as the client timeout keep applying.
+func (c *Client) wrapMiddleware() *http.Client {
+	if len(c.middleware) == 0 {
+		return nil
+	}
+
+	transport := c.httpClient.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	for i := len(c.middleware) - 1; i >= 0; i-- {
+		transport = c.middleware[i](transport)
+	}
+
+	client := *c.httpClient
+	client.Transport = transport
+	return &client
+}
@@ -0,0 +1,152 @@
+package lettermint
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestWithMiddleware_WrapsAllRequestPaths(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("X-Injected"); got != "yes" {
+			t.Errorf("%s X-Injected = %q, want yes", r.URL.Path, got)
+		}
+		switch r.URL.Path {
+		case "/send":
+			_ = json.NewEncoder(w).Encode(SendResponse{MessageID: "msg_123", Status: "queued"})
+		case "/send/batch":
+			_ = json.NewEncoder(w).Encode([]SendResponse{{MessageID: "msg_123", Status: "queued"}})
+		default:
+			_, _ = w.Write([]byte("pong"))
+		}
+	}))
+	defer server.Close()
+
+	var paths []string
+	inject := func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			paths = append(paths, req.URL.Path)
+			req.Header.Set("X-Injected", "yes")
+			return next.RoundTrip(req)
+		})
+	}
+
+	client, _ := New("test-token", WithBaseURL(server.URL), WithMiddleware(inject))
+
+	if _, err := client.Email(context.Background()).
+		From("sender@example.com").
+		To("recipient@example.com").
+		Subject("Test").
+		Text("Body").
+		Send(); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if _, err := client.SendBatch(context.Background(), SendBatchMailRequest{{From: "sender@example.com"}}); err != nil {
+		t.Fatalf("SendBatch() error = %v", err)
+	}
+	if _, err := client.Ping(context.Background()); err != nil {
+		t.Fatalf("Ping() error = %v", err)
+	}
+
+	api, _ := NewAPI("api-token", WithBaseURL(server.URL), WithMiddleware(inject))
+	if _, err := api.Messages.Text(context.Background(), "msg_123"); err != nil {
+		t.Fatalf("Messages.Text() error = %v", err)
+	}
+
+	want := []string{"/send", "/send/batch", "/ping", "/messages/msg_123/text"}
+	if !reflect.DeepEqual(paths, want) {
+		t.Fatalf("middleware saw %#v, want %#v", paths, want)
+	}
+}
+
+func TestWithMiddleware_Order(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("pong"))
+	}))
+	defer server.Close()
+
+	var order []string
+	record := func(name string) Middleware {
+		return func(next http.RoundTripper) http.RoundTripper {
+			return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				order = append(order, name)
+				return next.RoundTrip(req)
+			})
+		}
+	}
+
+	client, _ := New("test-token",
+		WithBaseURL(server.URL),
+		WithMiddleware(record("first"), record("second")),
+		WithMiddleware(record("third")),
+	)
+	if _, err := client.Ping(context.Background()); err != nil {
+		t.Fatalf("Ping() error = %v", err)
+	}
+
+	want := []string{"first", "second", "third"}
+	if !reflect.DeepEqual(order, want) {
+		t.Fatalf("middleware order = %#v, want %#v", order, want)
+	}
+}
+
+func TestWithMiddleware_KeepsHTTPClientSettings(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+		_, _ = w.Write([]byte("pong"))
+	}))
+	defer server.Close()
+
+	var transportUsed bool
+	httpClient := &http.Client{Transport: RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		transportUsed = true
+		return http.DefaultTransport.RoundTrip(req)
+	})}
+	passthrough := func(next http.RoundTripper) http.RoundTripper { return next }
+
+	client, _ := New("test-token",
+		WithBaseURL(server.URL),
+		WithHTTPClient(httpClient),
+		WithTimeout(20*time.Millisecond),
+		WithMiddleware(passthrough),
+	)
+	if _, err := client.Ping(context.Background()); err == nil {
+		t.Fatal("Ping() expected timeout error, got nil")
+	}
+	if !transportUsed {
+		t.Fatal("middleware did not wrap the configured transport")
+	}
+	if httpClient.Transport == nil || client.httpClient != httpClient {
+		t.Fatal("middleware modified the configured HTTP client")
+	}
+}
+
+func TestWithMiddleware_ConstructedOnce(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("pong"))
+	}))
+	defer server.Close()
+
+	constructed, requests := 0, 0
+	count := func(next http.RoundTripper) http.RoundTripper {
+		constructed++
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			requests++
+			return next.RoundTrip(req)
+		})
+	}
+
+	client, _ := New("test-token", WithBaseURL(server.URL), WithMiddleware(count))
+	for i := 0; i < 3; i++ {
+		if _, err := client.Ping(context.Background()); err != nil {
+			t.Fatalf("Ping() error = %v", err)
+		}
+	}
+	if constructed != 1 || requests != 3 {
+		t.Fatalf("constructed = %d, requests = %d, want 1 and 3", constructed, requests)
+	}
+}
@@ -0,0 +1,97 @@
+package lettermint
+
+import (
+	"context"
+	"time"
+)
+
+// Operation describes a single logical SDK operation, such as Messages.List
+// or Email.Send.
+//
+// An operation covers all attempts made for it, including retries.
+type Operation struct {
+	// Name identifies the operation, e.g. "Domains.VerifyDNSRecords".
+	Name string
+
+	// Method is the HTTP method of the request.
+	Method string
+
+	// Path is the request path relative to the base URL, e.g. "/messages/msg_123".
+	Path string
+
+	// MessageID is the message the operation relates to, when known.
+	// For Email.Send it is set from the response.
+	MessageID string
+
+	// Tag is the tag of the email being sent, if any.
+	Tag string
+
+	// Start is when the operation started.
+	Start time.Time
+
+	// Duration is how long the operation took. Set when the operation finishes.
+	Duration time.Duration
+
+	// StatusCode is the HTTP status code of the last attempt, or 0 if no
+	// response was received.
+	StatusCode int
+
+	// Attempts is the number of HTTP requests made for the operation.
+	Attempts int
+
+	// Err is the error returned by the operation, if any.
+	Err error
+}
+
+// Retries returns the number of attempts made after the first one.
+func (op *Operation) Retries() int {
+	if op.Attempts < 2 {
+		return 0
+	}
+	return op.Attempts - 1
+}
+
+// Observer is notified about every operation performed by a client.
+//
+// Use an Observer to integrate tracing or metrics. The lettermintotel
+// subpackage provides an OpenTelemetry implementation.
+type Observer interface {
+	// StartOperation is called before the first attempt of an operation.
+	// The returned context is used for all requests of the operation and is
+	// passed to FinishOperation.
+	StartOperation(ctx context.Context, op *Operation) context.Context
+
+	// FinishOperation is called once the operation has completed.
+	FinishOperation(ctx context.Context, op *Operation)
+}
+
+// operation returns a new operation with the given name.
+func operation(name string) *Operation {
+	return &Operation{Name: name}
+}
+
+// messageOperation returns a new operation relating to the given message.
+func messageOperation(name, messageID string) *Operation {
+	return &Operation{Name: name, MessageID: messageID}
+}
+
+// observe runs fn as the given operation, notifying the client's observers.
+func (c *Client) observe(ctx context.Context, op *Operation, method, path string, fn func(ctx context.Context) error) error {
+	op.Method = method
+	op.Path = path
+	op.Start = time.Now()
+	contexts := make([]context.Context, len(c.observers))
+	for i, observer := range c.observers {
+		ctx = observer.StartOperation(ctx, op)
+		contexts[i] = ctx
+	}
+
+	err := fn(ctx)
+
+	op.Duration = time.Since(op.Start)
+	op.Err = err
+	for i := len(c.observers) - 1; i >= 0; i-- {
+		c.observers[i].FinishOperation(contexts[i], op)
+	}
+	return err
+}
@@ -0,0 +1,117 @@
+package lettermint
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type observerContextKey struct{}
+
+type recordingObserver struct {
+	started  []string
+	finished []Operation
+}
+
+func (o *recordingObserver) StartOperation(ctx context.Context, op *Operation) context.Context {
+	o.started = append(o.started, op.Name)
+	return context.WithValue(ctx, observerContextKey{}, op.Name)
+}
+
+func (o *recordingObserver) FinishOperation(ctx context.Context, op *Operation) {
+	if ctx.Value(observerContextKey{}) != op.Name {
+		panic("FinishOperation received a context not returned by StartOperation")
+	}
+	o.finished = append(o.finished, *op)
+}
+
+func TestWithObserver_SendOperation(t *testing.T) {
+	count := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		count++
+		if count == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(SendResponse{MessageID: "msg_123", Status: "queued"})
+	}))
+	defer server.Close()
+
+	observer := &recordingObserver{}
+	client, _ := New("test-token",
+		WithBaseURL(server.URL),
+		WithRetryPolicy(testRetryPolicy()),
+		WithObserver(observer),
+	)
+
+	if _, err := client.Email(context.Background()).
+		From("sender@example.com").
+		To("recipient@example.com").
+		Subject("Test").
+		Text("Body").
+		Tag("welcome").
+		IdempotencyKey("key").
+		Send(); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	if len(observer.finished) != 1 {
+		t.Fatalf("finished operations = %d, want 1", len(observer.finished))
+	}
+	op := observer.finished[0]
+	if op.Name != "Email.Send" || op.Method != http.MethodPost || op.Path != "/send" {
+		t.Fatalf("operation = %s %s %s, want Email.Send POST /send", op.Name, op.Method, op.Path)
+	}
+	if op.MessageID != "msg_123" || op.Tag != "welcome" {
+		t.Fatalf("operation MessageID = %q, Tag = %q", op.MessageID, op.Tag)
+	}
+	if op.StatusCode != http.StatusOK || op.Attempts != 2 || op.Retries() != 1 {
+		t.Fatalf("operation StatusCode = %d, Attempts = %d, Retries = %d", op.StatusCode, op.Attempts, op.Retries())
+	}
+	if op.Err != nil || op.Duration <= 0 {
+		t.Fatalf("operation Err = %v, Duration = %v", op.Err, op.Duration)
+	}
+}
+
+func TestWithObserver_APIOperations(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/messages/msg_404" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"data": []any{}})
+	}))
+	defer server.Close()
+
+	first := &recordingObserver{}
+	second := &recordingObserver{}
+	api, _ := NewAPI("api-token", WithBaseURL(server.URL), WithObserver(first), WithObserver(second))
+
+	if _, err := api.Domains.VerifyDNSRecords(context.Background(), "domain_123"); err != nil {
+		t.Fatalf("Domains.VerifyDNSRecords() error = %v", err)
+	}
+	_, err := api.Messages.Retrieve(context.Background(), "msg_404")
+	if err == nil {
+		t.Fatal("Messages.Retrieve() expected error, got nil")
+	}
+
+	for _, observer := range []*recordingObserver{first, second} {
+		if len(observer.finished) != 2 {
+			t.Fatalf("finished operations = %d, want 2", len(observer.finished))
+		}
+		verify := observer.finished[0]
+		if verify.Name != "Domains.VerifyDNSRecords" || verify.Path != "/domains/domain_123/dns-records/verify" {
+			t.Fatalf("first operation = %s %s", verify.Name, verify.Path)
+		}
+		retrieve := observer.finished[1]
+		if retrieve.Name != "Messages.Retrieve" || retrieve.MessageID != "msg_404" {
+			t.Fatalf("second operation = %s, MessageID = %q", retrieve.Name, retrieve.MessageID)
+		}
+		if retrieve.StatusCode != http.StatusNotFound || !errors.Is(retrieve.Err, err) {
+			t.Fatalf("second operation StatusCode = %d, Err = %v", retrieve.StatusCode, retrieve.Err)
+		}
+	}
+}
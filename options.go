@@ -1,6 +1,7 @@
 package lettermint
 
 import (
+	"log/slog"
 	"net/http"
 	"time"
 )
@@ -39,3 +40,178 @@ func WithHTTPClient(client *http.Client) Option {
 		c.httpClient = client
 	}
 }
+
+// WithRetryPolicy enables automatic retries using the given policy.
+//
+// By default, the client makes a single attempt per request.
+// Use DefaultRetryPolicy() as a starting point:
+//
+//	client, err := lettermint.New("your-api-token",
+//	    lettermint.WithRetryPolicy(lettermint.DefaultRetryPolicy()),
+//	)
+//
+// POST requests, including Send, are only retried when they carry an
+// idempotency key.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *Client) {
+		c.retryPolicy = policy
+	}
+}
+
+// WithIdempotencyKeys makes the client generate a random idempotency key for
+// every Send and SendBatch call and every EmailBuilder.Build that does not
+// set one explicitly.
+//
+// The key is generated once per logical send and reused for all of its
+// retries, so a network failure during a send can never deliver the same
+// email twice. Combined with WithRetryPolicy, this also allows sends to be
+// retried automatically.
+func WithIdempotencyKeys() Option {
+	return WithIdempotencyKeyFunc(randomIdempotencyKey)
+}
+
+// WithIdempotencyKeyFunc makes the client derive the idempotency key for
+// every Send and SendBatch call and every EmailBuilder.Build that does not
+// set one explicitly.
+//
+// Use this to derive keys from your own entity IDs, for example with
+// IdempotencyKeyFromMetadata. Keys set with EmailBuilder.IdempotencyKey
+// take precedence.
+func WithIdempotencyKeyFunc(fn IdempotencyKeyFunc) Option {
+	return func(c *Client) {
+		c.idempotencyKeys = fn
+	}
+}
+
+// WithRateLimit limits the client to rps requests per second, allowing
+// bursts of up to burst requests.
+//
+// All requests of the client, including Send, share the limit. Requests
+// block until they may be sent, or until their context is done. The limiter
+// also pauses when the API reports that the rate limit is exhausted, so
+// goroutines sharing a client wait instead of receiving ErrRateLimited.
+//
+// A non-positive rps disables the limiter.
+func WithRateLimit(rps float64, burst int) Option {
+	return func(c *Client) {
+		if rps <= 0 {
+			c.rateLimiter = nil
+			return
+		}
+		c.rateLimiter = newRateLimiter(rps, burst)
+	}
+}
+
+// WithCircuitBreaker enables a circuit breaker that stops sending requests
+// while the API is failing.
+//
+// After config.FailureThreshold consecutive server errors, timeouts or
+// network errors, the circuit opens and all requests fail immediately with
+// ErrCircuitOpen. Once config.CoolDown has elapsed, a trial request is let
+// through: if it succeeds the circuit closes, otherwise it opens again.
+// Use DefaultCircuitBreakerConfig() as a starting point:
+//
+//	config := lettermint.DefaultCircuitBreakerConfig()
+//	config.OnStateChange = func(from, to lettermint.CircuitState) {
+//	    log.Printf("lettermint circuit %s -> %s", from, to)
+//	}
+//	client, err := lettermint.New("your-api-token", lettermint.WithCircuitBreaker(config))
+//
+// Every attempt counts, including retries made by the retry policy.
+func WithCircuitBreaker(config CircuitBreakerConfig) Option {
+	return func(c *Client) {
+		c.circuitBreaker = newCircuitBreaker(config)
+	}
+}
+
+// WithAttachmentPolicy checks the attachments of every email before it is
+// sent, so that a blocked file type or an oversized payload fails with an
+// *AttachmentError naming the attachment instead of a server error.
+//
+// The blocked file types are fetched with a Team API client and cached:
+//
+//	api, err := lettermint.NewAPI("your-team-token")
+//	client, err := lettermint.New("your-api-token",
+//	    lettermint.WithAttachmentPolicy(lettermint.AttachmentPolicy{
+//	        BlockedFileTypes: api,
+//	        MaxPayloadBytes:  25 << 20,
+//	    }),
+//	)
+//
+// The content of every attachment is read to sniff its MIME type and
+// determine its size, so streamed attachments are read twice. Sniffing
+// recognizes executables and the formats of http.DetectContentType.
+func WithAttachmentPolicy(policy AttachmentPolicy) Option {
+	return func(c *Client) {
+		c.attachmentPolicy = newAttachmentPolicy(policy)
+	}
+}
+
+// WithTextFromHTML derives the plain text body of every email from its HTML
+// body, unless a text body is set, instead of relying on the plaintext
+// generation of the server. See HTMLToText and EmailBuilder.TextFromHTML.
+func WithTextFromHTML() Option {
+	return func(c *Client) {
+		c.textFromHTML = true
+	}
+}
+
+// WithMaxResponseBytes limits the size of response bodies read by the client.
+//
+// Calls whose response exceeds the limit fail with ErrResponseTooLarge
+// instead of buffering the whole body in memory. Error responses are
+// truncated to the limit. By default, response sizes are not limited.
+// Use LimitResponseBytes to override the limit for a single call.
+//
+// A non-positive maxBytes disables the limit.
+func WithMaxResponseBytes(maxBytes int64) Option {
+	return func(c *Client) {
+		c.maxResponseBytes = maxBytes
+	}
+}
+
+// WithMiddleware adds middleware that wraps every request made by the client,
+// including Send and retried attempts.
+//
+// Middleware is applied in the order given: the first middleware is the
+// outermost and sees the request first. The option can be used multiple
+// times; later middleware is nested inside earlier middleware.
+//
+// Unlike WithHTTPClient, middleware keeps the client's timeout and transport
+// settings.
+func WithMiddleware(middleware ...Middleware) Option {
+	return func(c *Client) {
+		c.middleware = append(c.middleware, middleware...)
+	}
+}
+
+// WithObserver registers an observer that is notified about every operation
+// performed by the client, such as Email.Send or Messages.List.
+//
+// Use this to integrate tracing and metrics, for example with the
+// OpenTelemetry observer from the lettermintotel subpackage.
+// The option can be used multiple times to register several observers.
+func WithObserver(observer Observer) Option {
+	return func(c *Client) {
+		c.observers = append(c.observers, observer)
+	}
+}
+
+// WithLogger logs a summary of every request and response to the given logger.
+//
+// API tokens are never logged. Use WithLogConfig to change log levels or to
+// include headers and bodies.
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *Client) {
+		c.logger = logger
+	}
+}
+
+// WithLogConfig configures what is logged by the logger set with WithLogger.
+//
+// By default, DefaultLogConfig() is used.
+func WithLogConfig(config LogConfig) Option {
+	return func(c *Client) {
+		c.logConfig = config
+	}
+}
@@ -0,0 +1,184 @@
+// Package lettermintotel provides OpenTelemetry tracing and metrics for the
+// Lettermint Go SDK.
+//
+// It is a separate module so the core SDK stays free of dependencies.
+// Register the observer when creating a client:
+//
+//	observer, err := lettermintotel.NewObserver()
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//
+//	client, err := lettermint.New("your-api-token", lettermint.WithObserver(observer))
+//
+// Every logical operation, such as Email.Send or Messages.List, produces one
+// client span covering all of its attempts, and is recorded in the following
+// metrics:
+//
+//   - lettermint.client.operations: number of operations
+//   - lettermint.client.errors: number of failed operations
+//   - lettermint.client.retries: number of retried attempts
+//   - lettermint.client.duration: operation duration in seconds
+package lettermintotel
+
+import (
+	"context"
+
+	lettermint "github.com/lettermint/lettermint-go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/lettermint/lettermint-go/otel"
+
+// Attribute keys set on spans and metrics.
+const (
+	OperationKey  = attribute.Key("lettermint.operation")
+	EndpointKey   = attribute.Key("lettermint.endpoint")
+	MessageIDKey  = attribute.Key("lettermint.message_id")
+	TagKey        = attribute.Key("lettermint.tag")
+	RetryCountKey = attribute.Key("lettermint.retry_count")
+	MethodKey     = attribute.Key("http.request.method")
+	StatusCodeKey = attribute.Key("http.response.status_code")
+)
+
+// Option configures an Observer.
+type Option func(*config)
+
+type config struct {
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+}
+
+// WithTracerProvider sets the tracer provider used to create spans.
+//
+// By default, the global tracer provider is used.
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return func(c *config) {
+		c.tracerProvider = provider
+	}
+}
+
+// WithMeterProvider sets the meter provider used to record metrics.
+//
+// By default, the global meter provider is used.
+func WithMeterProvider(provider metric.MeterProvider) Option {
+	return func(c *config) {
+		c.meterProvider = provider
+	}
+}
+
+// Observer implements lettermint.Observer using OpenTelemetry.
+//
+// The observer is safe for concurrent use and can be shared by several clients.
+type Observer struct {
+	tracer     trace.Tracer
+	operations metric.Int64Counter
+	errors     metric.Int64Counter
+	retries    metric.Int64Counter
+	duration   metric.Float64Histogram
+}
+
+var _ lettermint.Observer = (*Observer)(nil)
+
+// NewObserver creates an OpenTelemetry observer.
+//
+// Returns an error if the metric instruments cannot be created.
+func NewObserver(opts ...Option) (*Observer, error) {
+	cfg := config{
+		tracerProvider: otel.GetTracerProvider(),
+		meterProvider:  otel.GetMeterProvider(),
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	meter := cfg.meterProvider.Meter(instrumentationName, metric.WithInstrumentationVersion(lettermint.Version))
+	o := &Observer{
+		tracer: cfg.tracerProvider.Tracer(instrumentationName, trace.WithInstrumentationVersion(lettermint.Version)),
+	}
+
+	var err error
+	if o.operations, err = meter.Int64Counter("lettermint.client.operations",
+		metric.WithDescription("Number of Lettermint operations."),
+		metric.WithUnit("{operation}"),
+	); err != nil {
+		return nil, err
+	}
+	if o.errors, err = meter.Int64Counter("lettermint.client.errors",
+		metric.WithDescription("Number of failed Lettermint operations."),
+		metric.WithUnit("{operation}"),
+	); err != nil {
+		return nil, err
+	}
+	if o.retries, err = meter.Int64Counter("lettermint.client.retries",
+		metric.WithDescription("Number of retried Lettermint requests."),
+		metric.WithUnit("{request}"),
+	); err != nil {
+		return nil, err
+	}
+	if o.duration, err = meter.Float64Histogram("lettermint.client.duration",
+		metric.WithDescription("Duration of Lettermint operations, including retries."),
+		metric.WithUnit("s"),
+	); err != nil {
+		return nil, err
+	}
+
+	return o, nil
+}
+
+// StartOperation starts a client span for the operation.
+func (o *Observer) StartOperation(ctx context.Context, op *lettermint.Operation) context.Context {
+	attrs := []attribute.KeyValue{
+		OperationKey.String(op.Name),
+		EndpointKey.String(op.Path),
+		MethodKey.String(op.Method),
+	}
+	if op.MessageID != "" {
+		attrs = append(attrs, MessageIDKey.String(op.MessageID))
+	}
+	if op.Tag != "" {
+		attrs = append(attrs, TagKey.String(op.Tag))
+	}
+
+	ctx, _ = o.tracer.Start(ctx, op.Name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithTimestamp(op.Start),
+		trace.WithAttributes(attrs...),
+	)
+	return ctx
+}
+
+// FinishOperation ends the operation's span and records its metrics.
+func (o *Observer) FinishOperation(ctx context.Context, op *lettermint.Operation) {
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(RetryCountKey.Int(op.Retries()))
+	if op.StatusCode != 0 {
+		span.SetAttributes(StatusCodeKey.Int(op.StatusCode))
+	}
+	if op.MessageID != "" {
+		span.SetAttributes(MessageIDKey.String(op.MessageID))
+	}
+	if op.Err != nil {
+		span.RecordError(op.Err)
+		span.SetStatus(codes.Error, op.Err.Error())
+	}
+	span.End(trace.WithTimestamp(op.Start.Add(op.Duration)))
+
+	attrs := []attribute.KeyValue{
+		OperationKey.String(op.Name),
+		StatusCodeKey.Int(op.StatusCode),
+	}
+	set := metric.WithAttributes(attrs...)
+	o.operations.Add(ctx, 1, set)
+	o.duration.Record(ctx, op.Duration.Seconds(), set)
+	if op.Retries() > 0 {
+		o.retries.Add(ctx, int64(op.Retries()), set)
+	}
+	if op.Err != nil {
+		o.errors.Add(ctx, 1, set)
+	}
+}
@@ -0,0 +1,129 @@
+package lettermintotel
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	lettermint "github.com/lettermint/lettermint-go"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func newTestObserver(t *testing.T) (*Observer, *tracetest.SpanRecorder, *sdkmetric.ManualReader) {
+	t.Helper()
+
+	spans := tracetest.NewSpanRecorder()
+	reader := sdkmetric.NewManualReader()
+	observer, err := NewObserver(
+		WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans))),
+		WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))),
+	)
+	if err != nil {
+		t.Fatalf("NewObserver() error = %v", err)
+	}
+	return observer, spans, reader
+}
+
+func TestObserver_SendSpan(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(lettermint.SendResponse{MessageID: "msg_123", Status: "queued"})
+	}))
+	defer server.Close()
+
+	observer, spans, _ := newTestObserver(t)
+	client, err := lettermint.New("test-token", lettermint.WithBaseURL(server.URL), lettermint.WithObserver(observer))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if _, err := client.Email(context.Background()).
+		From("sender@example.com").
+		To("recipient@example.com").
+		Subject("Test").
+		Text("Body").
+		Tag("welcome").
+		Send(); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	ended := spans.Ended()
+	if len(ended) != 1 {
+		t.Fatalf("spans = %d, want 1", len(ended))
+	}
+	span := ended[0]
+	if span.Name() != "Email.Send" {
+		t.Fatalf("span name = %q, want Email.Send", span.Name())
+	}
+	if span.SpanKind() != trace.SpanKindClient {
+		t.Fatalf("span kind = %v, want client", span.SpanKind())
+	}
+
+	attrs := attributeMap(span.Attributes())
+	want := map[attribute.Key]string{
+		OperationKey:  "Email.Send",
+		EndpointKey:   "/send",
+		MessageIDKey:  "msg_123",
+		TagKey:        "welcome",
+		StatusCodeKey: "200",
+		RetryCountKey: "0",
+	}
+	for key, value := range want {
+		if attrs[key] != value {
+			t.Errorf("attribute %s = %q, want %q", key, attrs[key], value)
+		}
+	}
+}
+
+func TestObserver_ErrorsAndMetrics(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	observer, spans, reader := newTestObserver(t)
+	api, err := lettermint.NewAPI("api-token", lettermint.WithBaseURL(server.URL), lettermint.WithObserver(observer))
+	if err != nil {
+		t.Fatalf("NewAPI() error = %v", err)
+	}
+
+	if _, err := api.Messages.List(context.Background(), nil); err == nil {
+		t.Fatal("Messages.List() expected error, got nil")
+	}
+
+	span := spans.Ended()[0]
+	if span.Name() != "Messages.List" || span.Status().Code != codes.Error {
+		t.Fatalf("span = %q with status %v, want Messages.List with error status", span.Name(), span.Status().Code)
+	}
+
+	var data metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &data); err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+	found := map[string]bool{}
+	for _, scope := range data.ScopeMetrics {
+		for _, m := range scope.Metrics {
+			found[m.Name] = true
+		}
+	}
+	for _, name := range []string{"lettermint.client.operations", "lettermint.client.errors", "lettermint.client.duration"} {
+		if !found[name] {
+			t.Errorf("metric %s not recorded", name)
+		}
+	}
+}
+
+func attributeMap(attrs []attribute.KeyValue) map[attribute.Key]string {
+	out := make(map[attribute.Key]string, len(attrs))
+	for _, attr := range attrs {
+		out[attr.Key] = attr.Value.Emit()
+	}
+	return out
+}
@@ -0,0 +1,134 @@
+package lettermint
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter_Reserve(t *testing.T) {
+	limiter := newRateLimiter(10, 2)
+	now := limiter.last
+
+	if delay := limiter.reserve(now); delay != 0 {
+		t.Fatalf("first reserve() = %v, want 0", delay)
+	}
+	if delay := limiter.reserve(now); delay != 0 {
+		t.Fatalf("second reserve() = %v, want 0", delay)
+	}
+	if delay := limiter.reserve(now); delay != 100*time.Millisecond {
+		t.Fatalf("third reserve() = %v, want 100ms", delay)
+	}
+	if delay := limiter.reserve(now.Add(100 * time.Millisecond)); delay != 0 {
+		t.Fatalf("reserve() after refill = %v, want 0", delay)
+	}
+}
+
+func TestRateLimiter_ObservePausesUntilReset(t *testing.T) {
+	limiter := newRateLimiter(100, 10)
+	now := limiter.last
+	reset := now.Add(30 * time.Second).Truncate(time.Second).Add(time.Second)
+
+	limiter.observe(&http.Response{
+		StatusCode: http.StatusOK,
+		Header: http.Header{
+			"X-Ratelimit-Remaining": {"0"},
+			"X-Ratelimit-Reset":     {strconv.FormatInt(reset.Unix(), 10)},
+		},
+	}, now)
+
+	if delay := limiter.reserve(now); delay != reset.Sub(now) {
+		t.Fatalf("reserve() = %v, want pause of %v", delay, reset.Sub(now))
+	}
+	if delay := limiter.reserve(reset); delay != 0 {
+		t.Fatalf("reserve() after reset = %v, want 0", delay)
+	}
+}
+
+func TestRateLimiter_ObserveTooManyRequests(t *testing.T) {
+	limiter := newRateLimiter(100, 10)
+	now := limiter.last
+
+	limiter.observe(&http.Response{
+		StatusCode: http.StatusTooManyRequests,
+		Header:     http.Header{"Retry-After": {"2"}},
+	}, now)
+
+	if delay := limiter.reserve(now); delay != 2*time.Second {
+		t.Fatalf("reserve() = %v, want 2s", delay)
+	}
+}
+
+func TestRateLimiter_ObserveRemainingCapsTokens(t *testing.T) {
+	limiter := newRateLimiter(1, 10)
+	now := limiter.last
+
+	limiter.observe(&http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"X-Ratelimit-Remaining": {"1"}},
+	}, now)
+
+	if delay := limiter.reserve(now); delay != 0 {
+		t.Fatalf("first reserve() = %v, want 0", delay)
+	}
+	if delay := limiter.reserve(now); delay == 0 {
+		t.Fatal("second reserve() = 0, want a delay after the server reported one remaining request")
+	}
+}
+
+func TestWithRateLimit_LimitsConcurrentRequests(t *testing.T) {
+	var mu sync.Mutex
+	var times []time.Time
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		times = append(times, time.Now())
+		mu.Unlock()
+		_, _ = w.Write([]byte("pong"))
+	}))
+	defer server.Close()
+
+	client, _ := New("test-token", WithBaseURL(server.URL), WithRateLimit(20, 1))
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := client.Ping(context.Background()); err != nil {
+				t.Errorf("Ping() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(times) != 5 {
+		t.Fatalf("requests = %d, want 5", len(times))
+	}
+	if elapsed := time.Since(start); elapsed < 190*time.Millisecond {
+		t.Fatalf("5 requests at 20 rps took %v, want at least 200ms", elapsed)
+	}
+}
+
+func TestWithRateLimit_RespectsContext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("pong"))
+	}))
+	defer server.Close()
+
+	client, _ := New("test-token", WithBaseURL(server.URL), WithRateLimit(0.1, 1))
+	if _, err := client.Ping(context.Background()); err != nil {
+		t.Fatalf("first Ping() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := client.Ping(ctx); !errors.Is(err, ErrTimeout) {
+		t.Fatalf("second Ping() error = %v, want ErrTimeout", err)
+	}
+}
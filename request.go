@@ -4,79 +4,169 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/url"
 	"runtime"
 	"strings"
+	"time"
 )
 
-func (c *Client) doJSON(ctx context.Context, method, path string, query map[string]string, payload interface{}, out interface{}) error {
+func (c *Client) doJSON(ctx context.Context, op *Operation, method, path string, query map[string]string, payload interface{}, out interface{}, opts ...CallOption) error {
 	body, err := requestBody(payload)
 	if err != nil {
 		return err
 	}
 
-	req, err := c.newRequest(ctx, method, path, query, body)
-	if err != nil {
-		return err
-	}
+	ctx, header, cancel := applyCallOptions(ctx, opts)
+	defer cancel()
+	return c.observe(ctx, op, method, path, func(ctx context.Context) error {
+		resp, err := c.do(ctx, op, method, path, query, bytesBody(body), header)
+		if err != nil {
+			return err
+		}
+		return c.decodeResponse(ctx, resp, out)
+	})
+}
 
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		if ctx.Err() == context.DeadlineExceeded {
-			return fmt.Errorf("%w: %v", ErrTimeout, err)
+func (c *Client) doRaw(ctx context.Context, op *Operation, method, path string, query map[string]string, opts ...CallOption) (string, error) {
+	ctx, header, cancel := applyCallOptions(ctx, opts)
+	defer cancel()
+
+	var raw string
+	err := c.observe(ctx, op, method, path, func(ctx context.Context) error {
+		resp, err := c.do(ctx, op, method, path, query, nil, header)
+		if err != nil {
+			return err
 		}
-		if ctx.Err() == context.Canceled {
-			return fmt.Errorf("request canceled: %w", err)
+		responseBody, err := c.readResponse(ctx, resp)
+		if err != nil {
+			return err
 		}
-		return fmt.Errorf("request failed: %w", err)
-	}
-	defer resp.Body.Close()
-
-	responseBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return fmt.Errorf("failed to read response: %w", err)
-	}
-	if resp.StatusCode >= 400 {
-		return parseAPIError(resp.StatusCode, responseBody)
-	}
-	if out == nil || len(responseBody) == 0 {
+		raw = string(responseBody)
 		return nil
-	}
-	if err := json.Unmarshal(responseBody, out); err != nil {
-		return fmt.Errorf("failed to parse response: %w", err)
-	}
-	return nil
+	})
+	return raw, err
 }
 
-func (c *Client) doRaw(ctx context.Context, method, path string, query map[string]string) (string, error) {
-	req, err := c.newRequest(ctx, method, path, query, nil)
-	if err != nil {
-		return "", err
-	}
+// do sends a request, retrying it according to the retry policy of the call.
+// Every attempt waits for the client's rate limiter and is checked against
+// the circuit breaker, if they are configured.
+//
+// It returns the response of the first successful attempt with an unread
+// body, or an error. Error responses are converted to an *APIError.
+func (c *Client) do(ctx context.Context, op *Operation, method, path string, query map[string]string, body bodySource, header http.Header) (*http.Response, error) {
+	policy := c.retryPolicyFor(ctx)
+	retryable := policy.allowsRetry(method, header)
 
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		if ctx.Err() == context.DeadlineExceeded {
-			return "", fmt.Errorf("%w: %v", ErrTimeout, err)
+	for attempt := 1; ; attempt++ {
+		if c.rateLimiter != nil {
+			if err := c.rateLimiter.wait(ctx); err != nil {
+				return nil, err
+			}
+		}
+
+		req, err := c.attemptRequest(ctx, method, path, query, body, header)
+		if err != nil {
+			return nil, err
+		}
+
+		report := func(error) {}
+		if c.circuitBreaker != nil {
+			if report, err = c.circuitBreaker.allow(); err != nil {
+				closeRequestBody(req)
+				c.logFailure(ctx, op, attempt, err)
+				return nil, err
+			}
+		}
+
+		op.Attempts = attempt
+		c.logRequest(ctx, op, req, body, attempt)
+		start := time.Now()
+		resp, err := c.send(req)
+		if err != nil {
+			err = transportError(ctx, err)
+			report(err)
+			c.logFailure(ctx, op, attempt, err)
+			if !retryable || attempt >= policy.MaxAttempts || !policy.retryableError(err) {
+				return nil, err
+			}
+			if err := sleep(ctx, policy.backoff(attempt)); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		op.StatusCode = resp.StatusCode
+		captureResponse(ctx, resp, attempt)
+		c.logResponse(ctx, op, resp, attempt, time.Since(start))
+		if c.rateLimiter != nil {
+			c.rateLimiter.observe(resp, time.Now())
+		}
+
+		if resp.StatusCode < 400 {
+			report(nil)
+			return resp, nil
 		}
-		if ctx.Err() == context.Canceled {
-			return "", fmt.Errorf("request canceled: %w", err)
+
+		responseBody, err := readErrorBody(resp, c.responseLimit(ctx))
+		if err != nil {
+			err = readError(ctx, err)
+			report(err)
+			c.logFailure(ctx, op, attempt, err)
+			if !retryable || attempt >= policy.MaxAttempts || !policy.retryableError(err) {
+				return nil, err
+			}
+			if err := sleep(ctx, policy.backoff(attempt)); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		apiErr := parseAPIError(resp.StatusCode, resp.Header, responseBody)
+		report(apiErr)
+		c.logFailure(ctx, op, attempt, apiErr)
+
+		if !retryable || attempt >= policy.MaxAttempts || !policy.retryableStatus(resp.StatusCode) {
+			return nil, apiErr
+		}
+		delay := policy.backoff(attempt)
+		if wait, ok := retryAfter(resp.StatusCode, resp.Header, time.Now()); ok {
+			if policy.MaxRetryAfter > 0 && wait > policy.MaxRetryAfter {
+				return nil, apiErr
+			}
+			if wait > delay {
+				delay = wait
+			}
+		}
+		if err := sleep(ctx, delay); err != nil {
+			return nil, err
 		}
-		return "", fmt.Errorf("request failed: %w", err)
 	}
-	defer resp.Body.Close()
+}
 
-	responseBody, err := io.ReadAll(resp.Body)
+// attemptRequest builds the request for a single attempt. The body is
+// reopened from its source so every attempt sends the full body.
+func (c *Client) attemptRequest(ctx context.Context, method, path string, query map[string]string, body bodySource, header http.Header) (*http.Request, error) {
+	var reader io.Reader
+	if body != nil {
+		var err error
+		if reader, err = body.reader(); err != nil {
+			return nil, err
+		}
+	}
+	req, err := c.newRequest(ctx, method, path, query, reader)
 	if err != nil {
-		return "", fmt.Errorf("failed to read response: %w", err)
+		if closer, ok := reader.(io.Closer); ok {
+			closer.Close()
+		}
+		return nil, err
 	}
-	if resp.StatusCode >= 400 {
-		return "", parseAPIError(resp.StatusCode, responseBody)
+	for key, values := range header {
+		req.Header[key] = values
 	}
-	return string(responseBody), nil
+	return req, nil
 }
 
 func (c *Client) newRequest(ctx context.Context, method, path string, query map[string]string, body io.Reader) (*http.Request, error) {
@@ -92,10 +182,14 @@ func (c *Client) newRequest(ctx context.Context, method, path string, query map[
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept", "application/json")
 	req.Header.Set("User-Agent", fmt.Sprintf("Lettermint/%s (Go; %s)", Version, runtime.Version()))
+	token, err := c.token(ctx)
+	if err != nil {
+		return nil, err
+	}
 	if c.authScheme == authSchemeBearer {
-		req.Header.Set("Authorization", "Bearer "+c.apiToken)
+		req.Header.Set("Authorization", "Bearer "+token)
 	} else {
-		req.Header.Set("x-lettermint-token", c.apiToken)
+		req.Header.Set("x-lettermint-token", token)
 	}
 
 	return req, nil
@@ -119,7 +213,38 @@ func (c *Client) url(path string, query map[string]string) (string, error) {
 	return resolved.String(), nil
 }
 
-func requestBody(payload interface{}) (io.Reader, error) {
+// bodySource provides the request body of every attempt of a request.
+type bodySource interface {
+	// reader returns the body of a new attempt. If it is an io.Closer, the
+	// body is closed when the attempt is done.
+	reader() (io.Reader, error)
+
+	// logBytes returns the body as it is logged.
+	logBytes() []byte
+}
+
+// bytesBody is a request body held in memory.
+type bytesBody []byte
+
+func (b bytesBody) reader() (io.Reader, error) {
+	if b == nil {
+		return nil, nil
+	}
+	return bytes.NewReader(b), nil
+}
+
+func (b bytesBody) logBytes() []byte {
+	return b
+}
+
+// closeRequestBody closes the body of a request that is not sent.
+func closeRequestBody(req *http.Request) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+}
+
+func requestBody(payload interface{}) ([]byte, error) {
 	if payload == nil {
 		return nil, nil
 	}
@@ -127,5 +252,60 @@ func requestBody(payload interface{}) (io.Reader, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal request payload: %w", err)
 	}
-	return bytes.NewReader(data), nil
+	return data, nil
+}
+
+// decodeResponse reads and closes a successful response, decoding the JSON
+// body into out when both are present.
+func (c *Client) decodeResponse(ctx context.Context, resp *http.Response, out interface{}) error {
+	responseBody, err := c.readResponse(ctx, resp)
+	if err != nil {
+		return err
+	}
+	if out == nil || len(responseBody) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(responseBody, out); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	return nil
+}
+
+// transportError wraps an error returned by the HTTP client so that it
+// matches ErrTimeout, ErrNetwork or context.Canceled.
+func transportError(ctx context.Context, err error) error {
+	var netErr net.Error
+	var attachmentErr *AttachmentError
+	switch {
+	case errors.As(err, &attachmentErr):
+		return fmt.Errorf("%w: %w", ErrInvalidRequest, attachmentErr)
+	case errors.Is(ctx.Err(), context.Canceled):
+		return fmt.Errorf("lettermint: request canceled: %w", err)
+	case errors.Is(ctx.Err(), context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return fmt.Errorf("%w: %w", ErrTimeout, err)
+	default:
+		return fmt.Errorf("%w: %w", ErrNetwork, err)
+	}
+}
+
+// readError wraps an error that occurred while reading a response body.
+// Truncated responses match ErrNetwork.
+func readError(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return transportError(ctx, err)
+	}
+	return fmt.Errorf("%w: failed to read response: %w", ErrNetwork, err)
+}
+
+// sleep waits for the given delay or until the context is done.
+func sleep(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return transportError(ctx, ctx.Err())
+	}
 }
@@ -0,0 +1,156 @@
+package lettermint
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// HeaderRequestID is the response header carrying the server's request ID.
+const HeaderRequestID = "X-Request-Id"
+
+// ResponseMetadata describes the HTTP response of an operation.
+//
+// Use CaptureResponse to obtain the metadata of a call.
+type ResponseMetadata struct {
+	// StatusCode is the HTTP status code of the response.
+	StatusCode int
+
+	// RequestID is the server's request ID. Include it in support requests.
+	RequestID string
+
+	// RateLimit is the rate limit state reported by the API, or nil if the
+	// response did not include rate limit headers.
+	RateLimit *RateLimit
+
+	// Header contains the raw response headers.
+	Header http.Header
+
+	// Attempts is the number of HTTP requests made, including retries.
+	Attempts int
+}
+
+// RateLimit is the rate limit state reported by the API.
+type RateLimit struct {
+	// Limit is the maximum number of requests in the current window,
+	// or -1 if not reported.
+	Limit int
+
+	// Remaining is the number of requests left in the current window,
+	// or -1 if not reported.
+	Remaining int
+
+	// Reset is when the current window resets. Zero if not reported.
+	Reset time.Time
+}
+
+type responseCaptureKey struct{}
+
+// CaptureResponse returns a context that records the response metadata of
+// the operation it is used for into meta.
+//
+// The metadata is filled for successful and failed calls alike, as soon as
+// a response is received. When an operation is retried, meta describes the
+// last response.
+//
+// Example:
+//
+//	var meta lettermint.ResponseMetadata
+//	domains, err := api.Domains.List(lettermint.CaptureResponse(ctx, &meta), nil)
+//	log.Printf("request %s returned %d", meta.RequestID, meta.StatusCode)
+func CaptureResponse(ctx context.Context, meta *ResponseMetadata) context.Context {
+	return context.WithValue(ctx, responseCaptureKey{}, meta)
+}
+
+// captureResponse records the response into the metadata captured by ctx, if any.
+func captureResponse(ctx context.Context, resp *http.Response, attempts int) {
+	meta, ok := ctx.Value(responseCaptureKey{}).(*ResponseMetadata)
+	if !ok || meta == nil {
+		return
+	}
+	*meta = ResponseMetadata{
+		StatusCode: resp.StatusCode,
+		RequestID:  resp.Header.Get(HeaderRequestID),
+		RateLimit:  parseRateLimit(resp.Header, time.Now()),
+		Header:     resp.Header,
+		Attempts:   attempts,
+	}
+}
+
+// parseRateLimit parses the X-RateLimit-* headers. Returns nil when none
+// of them is present.
+func parseRateLimit(header http.Header, now time.Time) *RateLimit {
+	limit, limitOK := headerInt(header, "X-RateLimit-Limit")
+	remaining, remainingOK := headerInt(header, "X-RateLimit-Remaining")
+	reset, resetOK := rateLimitReset(header, now)
+	if !limitOK && !remainingOK && !resetOK {
+		return nil
+	}
+	return &RateLimit{Limit: limit, Remaining: remaining, Reset: reset}
+}
+
+// headerInt parses a non-negative integer header, returning -1 when the
+// header is missing or invalid.
+func headerInt(header http.Header, key string) (int, bool) {
+	value, err := strconv.Atoi(header.Get(key))
+	if err != nil || value < 0 {
+		return -1, false
+	}
+	return value, true
+}
+
+type responseLimitKey struct{}
+
+// LimitResponseBytes returns a context that overrides the client's response
+// size limit, set with WithMaxResponseBytes, for the calls it is used for.
+//
+// A non-positive maxBytes disables the limit for these calls.
+//
+// Example:
+//
+//	ctx := lettermint.LimitResponseBytes(ctx, 10<<20)
+//	messages, err := api.Messages.List(ctx, query)
+//	if errors.Is(err, lettermint.ErrResponseTooLarge) {
+//	    // Use a smaller page size
+//	}
+func LimitResponseBytes(ctx context.Context, maxBytes int64) context.Context {
+	return context.WithValue(ctx, responseLimitKey{}, maxBytes)
+}
+
+// responseLimit returns the response size limit for a call, or zero if
+// response sizes are not limited.
+func (c *Client) responseLimit(ctx context.Context) int64 {
+	limit := c.maxResponseBytes
+	if override, ok := ctx.Value(responseLimitKey{}).(int64); ok {
+		limit = override
+	}
+	if limit < 0 {
+		return 0
+	}
+	return limit
+}
+
+// readResponse reads and closes a successful response body, enforcing the
+// response size limit of the call.
+func (c *Client) readResponse(ctx context.Context, resp *http.Response) ([]byte, error) {
+	defer resp.Body.Close()
+
+	limit := c.responseLimit(ctx)
+	if limit > 0 && resp.ContentLength > limit {
+		return nil, responseTooLarge(limit)
+	}
+	return io.ReadAll(&responseReader{ctx: ctx, body: resp.Body, limit: limit, remaining: limit})
+}
+
+// readErrorBody reads and closes an error response body, truncating it to
+// limit bytes if limit is positive.
+func readErrorBody(resp *http.Response, limit int64) ([]byte, error) {
+	defer resp.Body.Close()
+
+	if limit <= 0 {
+		return io.ReadAll(resp.Body)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, limit))
+}
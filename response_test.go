@@ -0,0 +1,135 @@
+package lettermint
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCaptureResponse_Success(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-Id", "req_123")
+		w.Header().Set("X-RateLimit-Limit", "60")
+		w.Header().Set("X-RateLimit-Remaining", "59")
+		w.Header().Set("X-RateLimit-Reset", "1700000000")
+		w.Header().Set("X-Custom", "value")
+		_ = json.NewEncoder(w).Encode(map[string]any{"data": []any{}})
+	}))
+	defer server.Close()
+
+	api, _ := NewAPI("api-token", WithBaseURL(server.URL))
+
+	var meta ResponseMetadata
+	if _, err := api.Domains.List(CaptureResponse(context.Background(), &meta), nil); err != nil {
+		t.Fatalf("Domains.List() error = %v", err)
+	}
+
+	if meta.StatusCode != http.StatusOK || meta.RequestID != "req_123" || meta.Attempts != 1 {
+		t.Fatalf("metadata = %+v", meta)
+	}
+	if meta.Header.Get("X-Custom") != "value" {
+		t.Fatalf("Header[X-Custom] = %q, want value", meta.Header.Get("X-Custom"))
+	}
+	if meta.RateLimit == nil {
+		t.Fatal("RateLimit = nil, want parsed rate limit")
+	}
+	if meta.RateLimit.Limit != 60 || meta.RateLimit.Remaining != 59 || !meta.RateLimit.Reset.Equal(time.Unix(1700000000, 0)) {
+		t.Fatalf("RateLimit = %+v", *meta.RateLimit)
+	}
+}
+
+func TestCaptureResponse_SendAndError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-Id", "req_456")
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write([]byte(`{"message":"Too many requests"}`))
+	}))
+	defer server.Close()
+
+	client, _ := New("test-token", WithBaseURL(server.URL))
+
+	var meta ResponseMetadata
+	_, err := client.Email(CaptureResponse(context.Background(), &meta)).
+		From("sender@example.com").
+		To("recipient@example.com").
+		Subject("Test").
+		Text("Body").
+		Send()
+	if !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("Send() error = %v, want ErrRateLimited", err)
+	}
+
+	if meta.StatusCode != http.StatusTooManyRequests || meta.RequestID != "req_456" || meta.RateLimit != nil {
+		t.Fatalf("metadata = %+v", meta)
+	}
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatal("Send() error should be *APIError")
+	}
+	if apiErr.RequestID != "req_456" {
+		t.Fatalf("APIError.RequestID = %q, want req_456", apiErr.RequestID)
+	}
+	if apiErr.RetryAfter != 30*time.Second {
+		t.Fatalf("APIError.RetryAfter = %v, want 30s", apiErr.RetryAfter)
+	}
+}
+
+func TestWithMaxResponseBytes(t *testing.T) {
+	listing := `{"data":[` + strings.Repeat(`{"id":"dom_1"},`, 100) + `{"id":"dom_2"}]}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/domains", "/messages/msg_1/source":
+			_, _ = w.Write([]byte(listing))
+		case "/ping":
+			// Flush before writing to send the body without Content-Length.
+			w.(http.Flusher).Flush()
+			_, _ = w.Write([]byte(strings.Repeat("pong", 100)))
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = w.Write([]byte(strings.Repeat("x", 1000)))
+		}
+	}))
+	defer server.Close()
+
+	api, _ := NewAPI("api-token", WithBaseURL(server.URL), WithMaxResponseBytes(256))
+	ctx := context.Background()
+
+	if _, err := api.Domains.List(ctx, nil); !errors.Is(err, ErrResponseTooLarge) {
+		t.Fatalf("Domains.List() error = %v, want ErrResponseTooLarge", err)
+	}
+	if _, err := api.Ping(ctx); !errors.Is(err, ErrResponseTooLarge) {
+		t.Fatalf("Ping() error = %v, want ErrResponseTooLarge", err)
+	}
+	if _, err := api.Messages.SourceStream(ctx, "msg_1", 0); !errors.Is(err, ErrResponseTooLarge) {
+		t.Fatalf("SourceStream() error = %v, want client limit to apply", err)
+	}
+	body, err := api.Messages.SourceStream(ctx, "msg_1", -1)
+	if err != nil {
+		t.Fatalf("SourceStream() without limit error = %v", err)
+	}
+	body.Close()
+
+	domains, err := api.Domains.List(LimitResponseBytes(ctx, int64(len(listing))), nil)
+	if err != nil || len(domains.Data) != 101 {
+		t.Fatalf("Domains.List() with larger limit = %d domains, %v", len(domains.Data), err)
+	}
+	if _, err := api.Ping(LimitResponseBytes(ctx, 0)); err != nil {
+		t.Fatalf("Ping() without limit error = %v", err)
+	}
+
+	_, err = api.Team.Retrieve(ctx)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("Team.Retrieve() error = %v, want *APIError", err)
+	}
+	if len(apiErr.ResponseBody) != 256 {
+		t.Fatalf("len(ResponseBody) = %d, want error body truncated to 256", len(apiErr.ResponseBody))
+	}
+}
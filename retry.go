@@ -0,0 +1,169 @@
+package lettermint
+
+import (
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy configures automatic retries for failed requests.
+//
+// Retries apply to every request made by the client, including Send.
+// Requests with non-idempotent methods (POST, PATCH) are only retried when
+// an Idempotency-Key header is present, so a retry can never cause a
+// duplicate side effect.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first one.
+	// Values below 2 disable retries.
+	MaxAttempts int
+
+	// InitialBackoff is the delay before the first retry.
+	InitialBackoff time.Duration
+
+	// MaxBackoff caps the delay between two attempts.
+	MaxBackoff time.Duration
+
+	// Multiplier is the factor applied to the delay after every attempt.
+	// Values below 1 are treated as 1.
+	Multiplier float64
+
+	// Jitter is the fraction of each delay that is randomized, between 0 and 1.
+	// A jitter of 0.2 spreads a 1s delay over 0.8s to 1.2s.
+	Jitter float64
+
+	// RetryableStatusCodes lists the HTTP status codes that trigger a retry.
+	RetryableStatusCodes []int
+
+	// RetryNetworkErrors enables retries for transport failures such as
+	// refused connections or reset streams.
+	RetryNetworkErrors bool
+
+	// MaxRetryAfter is the longest server-requested delay (Retry-After or
+	// rate limit reset headers) the client is willing to wait. When the server
+	// asks for a longer delay, the error is returned instead.
+	// Zero means the server-requested delay is always honored.
+	MaxRetryAfter time.Duration
+}
+
+// DefaultRetryPolicy returns a retry policy suitable for most applications.
+//
+// It makes up to 3 attempts with exponential backoff starting at 500ms,
+// retries rate limits, server errors and network errors, and honors
+// Retry-After headers up to one minute.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: 500 * time.Millisecond,
+		MaxBackoff:     30 * time.Second,
+		Multiplier:     2,
+		Jitter:         0.2,
+		RetryableStatusCodes: []int{
+			http.StatusRequestTimeout,
+			http.StatusTooManyRequests,
+			http.StatusInternalServerError,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		},
+		RetryNetworkErrors: true,
+		MaxRetryAfter:      time.Minute,
+	}
+}
+
+// allowsRetry reports whether a request may be retried at all.
+func (p RetryPolicy) allowsRetry(method string, header http.Header) bool {
+	if p.MaxAttempts < 2 {
+		return false
+	}
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return header.Get("Idempotency-Key") != ""
+	}
+}
+
+// retryableStatus reports whether the status code is configured as retryable.
+func (p RetryPolicy) retryableStatus(statusCode int) bool {
+	for _, code := range p.RetryableStatusCodes {
+		if code == statusCode {
+			return true
+		}
+	}
+	return false
+}
+
+// retryableError reports whether a failed attempt that produced no response
+// should be retried.
+func (p RetryPolicy) retryableError(err error) bool {
+	return p.RetryNetworkErrors && IsRetryable(err)
+}
+
+// backoff returns the delay before the given retry, where retry 1 is the
+// first retry after the initial attempt.
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+	delay := float64(p.InitialBackoff) * math.Pow(multiplier, float64(retry-1))
+	if p.MaxBackoff > 0 && delay > float64(p.MaxBackoff) {
+		delay = float64(p.MaxBackoff)
+	}
+	if p.Jitter > 0 {
+		jitter := math.Min(p.Jitter, 1)
+		delay *= 1 + jitter*(2*rand.Float64()-1)
+	}
+	if p.MaxBackoff > 0 && delay > float64(p.MaxBackoff) {
+		delay = float64(p.MaxBackoff)
+	}
+	return time.Duration(delay)
+}
+
+// retryAfter returns the delay requested by the server through the
+// Retry-After header. For a rate limited response, one with status 429 or
+// no remaining requests, it falls back to the X-RateLimit-Reset header,
+// which otherwise only says when the current window ends.
+func retryAfter(statusCode int, header http.Header, now time.Time) (time.Duration, bool) {
+	if value := header.Get("Retry-After"); value != "" {
+		if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+			return time.Duration(seconds) * time.Second, true
+		}
+		if date, err := http.ParseTime(value); err == nil {
+			return nonNegative(date.Sub(now)), true
+		}
+	}
+	if remaining, ok := headerInt(header, "X-RateLimit-Remaining"); statusCode != http.StatusTooManyRequests && (!ok || remaining > 0) {
+		return 0, false
+	}
+	if reset, ok := rateLimitReset(header, now); ok {
+		return nonNegative(reset.Sub(now)), true
+	}
+	return 0, false
+}
+
+// rateLimitReset parses the X-RateLimit-Reset header. Values that look like
+// a Unix timestamp are treated as such; smaller values are a delay in seconds.
+func rateLimitReset(header http.Header, now time.Time) (time.Time, bool) {
+	value := header.Get("X-RateLimit-Reset")
+	if value == "" {
+		return time.Time{}, false
+	}
+	reset, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || reset < 0 {
+		return time.Time{}, false
+	}
+	if reset >= 1_000_000_000 {
+		return time.Unix(reset, 0), true
+	}
+	return now.Add(time.Duration(reset) * time.Second), true
+}
+
+func nonNegative(d time.Duration) time.Duration {
+	if d < 0 {
+		return 0
+	}
+	return d
+}
@@ -0,0 +1,287 @@
+package lettermint
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func testRetryPolicy() RetryPolicy {
+	policy := DefaultRetryPolicy()
+	policy.InitialBackoff = time.Millisecond
+	policy.MaxBackoff = 5 * time.Millisecond
+	policy.Jitter = 0
+	return policy
+}
+
+func TestRetryPolicy_RetriesIdempotentRequests(t *testing.T) {
+	count := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		count++
+		if count < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte(`{"message":"try again"}`))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"id": "ok"})
+	}))
+	defer server.Close()
+
+	api, err := NewAPI("api-token", WithBaseURL(server.URL), WithRetryPolicy(testRetryPolicy()))
+	if err != nil {
+		t.Fatalf("NewAPI() error = %v", err)
+	}
+
+	if _, err := api.Domains.Retrieve(context.Background(), "domain_123"); err != nil {
+		t.Fatalf("Domains.Retrieve() error = %v", err)
+	}
+	if count != 3 {
+		t.Fatalf("attempts = %d, want 3", count)
+	}
+}
+
+func TestRetryPolicy_StopsAfterMaxAttempts(t *testing.T) {
+	count := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		count++
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	api, _ := NewAPI("api-token", WithBaseURL(server.URL), WithRetryPolicy(testRetryPolicy()))
+
+	_, err := api.Team.Retrieve(context.Background())
+	if !errors.Is(err, ErrServerError) {
+		t.Fatalf("Team.Retrieve() error = %v, want ErrServerError", err)
+	}
+	if count != 3 {
+		t.Fatalf("attempts = %d, want 3", count)
+	}
+}
+
+func TestRetryPolicy_DoesNotRetryNonRetryableStatus(t *testing.T) {
+	count := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		count++
+		w.WriteHeader(http.StatusUnprocessableEntity)
+	}))
+	defer server.Close()
+
+	api, _ := NewAPI("api-token", WithBaseURL(server.URL), WithRetryPolicy(testRetryPolicy()))
+
+	if _, err := api.Team.Retrieve(context.Background()); !errors.Is(err, ErrValidation) {
+		t.Fatalf("Team.Retrieve() error = %v, want ErrValidation", err)
+	}
+	if count != 1 {
+		t.Fatalf("attempts = %d, want 1", count)
+	}
+}
+
+func TestRetryPolicy_SendRequiresIdempotencyKey(t *testing.T) {
+	var bodies []string
+	var keys []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		keys = append(keys, r.Header.Get("Idempotency-Key"))
+		if len(bodies)%2 == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(SendResponse{MessageID: "msg_123", Status: "queued"})
+	}))
+	defer server.Close()
+
+	client, _ := New("test-token", WithBaseURL(server.URL), WithRetryPolicy(testRetryPolicy()))
+
+	_, err := client.Email(context.Background()).
+		From("sender@example.com").
+		To("recipient@example.com").
+		Subject("Test").
+		Text("Body").
+		Send()
+	if !errors.Is(err, ErrServerError) {
+		t.Fatalf("Send() without key error = %v, want ErrServerError", err)
+	}
+	if len(bodies) != 1 {
+		t.Fatalf("attempts without key = %d, want 1", len(bodies))
+	}
+
+	bodies, keys = nil, nil
+	resp, err := client.Email(context.Background()).
+		From("sender@example.com").
+		To("recipient@example.com").
+		Subject("Test").
+		Text("Body").
+		IdempotencyKey("key-123").
+		Send()
+	if err != nil {
+		t.Fatalf("Send() with key error = %v", err)
+	}
+	if resp.MessageID != "msg_123" {
+		t.Fatalf("MessageID = %q, want msg_123", resp.MessageID)
+	}
+	if len(bodies) != 2 {
+		t.Fatalf("attempts with key = %d, want 2", len(bodies))
+	}
+	if bodies[0] == "" || bodies[0] != bodies[1] {
+		t.Fatalf("retry body = %q, want %q", bodies[1], bodies[0])
+	}
+	if keys[0] != "key-123" || keys[1] != "key-123" {
+		t.Fatalf("Idempotency-Key = %#v, want key-123 on every attempt", keys)
+	}
+}
+
+func TestRetryPolicy_HonorsRetryAfter(t *testing.T) {
+	var times []time.Time
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		times = append(times, time.Now())
+		if len(times) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		_, _ = w.Write([]byte("pong"))
+	}))
+	defer server.Close()
+
+	api, _ := NewAPI("api-token", WithBaseURL(server.URL), WithRetryPolicy(testRetryPolicy()))
+
+	if _, err := api.Ping(context.Background()); err != nil {
+		t.Fatalf("Ping() error = %v", err)
+	}
+	if len(times) != 2 {
+		t.Fatalf("attempts = %d, want 2", len(times))
+	}
+	if waited := times[1].Sub(times[0]); waited < time.Second {
+		t.Fatalf("waited %v between attempts, want at least 1s", waited)
+	}
+}
+
+func TestRetryPolicy_RetryAfterAboveLimitReturnsError(t *testing.T) {
+	count := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		count++
+		w.Header().Set("Retry-After", "3600")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	api, _ := NewAPI("api-token", WithBaseURL(server.URL), WithRetryPolicy(testRetryPolicy()))
+
+	if _, err := api.Ping(context.Background()); !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("Ping() error = %v, want ErrRateLimited", err)
+	}
+	if count != 1 {
+		t.Fatalf("attempts = %d, want 1", count)
+	}
+}
+
+func TestRetryPolicy_ServerErrorIgnoresRateLimitReset(t *testing.T) {
+	count := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		count++
+		w.Header().Set("X-RateLimit-Remaining", "40")
+		w.Header().Set("X-RateLimit-Reset", "3600")
+		if count < 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte("pong"))
+	}))
+	defer server.Close()
+
+	api, _ := NewAPI("api-token", WithBaseURL(server.URL), WithRetryPolicy(testRetryPolicy()))
+
+	if _, err := api.Ping(context.Background()); err != nil {
+		t.Fatalf("Ping() error = %v, want the server error retried with backoff", err)
+	}
+	if count != 2 {
+		t.Fatalf("attempts = %d, want 2", count)
+	}
+}
+
+func TestRetryPolicy_ContextCanceledDuringBackoff(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	policy := testRetryPolicy()
+	policy.InitialBackoff = time.Minute
+	policy.MaxBackoff = time.Minute
+	api, _ := NewAPI("api-token", WithBaseURL(server.URL), WithRetryPolicy(policy))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if _, err := api.Ping(ctx); !errors.Is(err, ErrTimeout) {
+		t.Fatalf("Ping() error = %v, want ErrTimeout", err)
+	}
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	policy := RetryPolicy{
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     time.Second,
+		Multiplier:     2,
+	}
+
+	tests := []struct {
+		retry int
+		want  time.Duration
+	}{
+		{retry: 1, want: 100 * time.Millisecond},
+		{retry: 2, want: 200 * time.Millisecond},
+		{retry: 3, want: 400 * time.Millisecond},
+		{retry: 5, want: time.Second},
+	}
+	for _, tt := range tests {
+		if got := policy.backoff(tt.retry); got != tt.want {
+			t.Errorf("backoff(%d) = %v, want %v", tt.retry, got, tt.want)
+		}
+	}
+
+	policy.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		got := policy.backoff(1)
+		if got < 50*time.Millisecond || got > 150*time.Millisecond {
+			t.Fatalf("backoff(1) with jitter = %v, want within [50ms, 150ms]", got)
+		}
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+
+	tests := []struct {
+		name   string
+		status int
+		header http.Header
+		want   time.Duration
+		wantOK bool
+	}{
+		{name: "seconds", status: 503, header: http.Header{"Retry-After": {"5"}}, want: 5 * time.Second, wantOK: true},
+		{name: "http date", status: 429, header: http.Header{"Retry-After": {now.Add(10 * time.Second).UTC().Format(http.TimeFormat)}}, want: 10 * time.Second, wantOK: true},
+		{name: "reset timestamp", status: 429, header: http.Header{"X-Ratelimit-Reset": {"1700000030"}}, want: 30 * time.Second, wantOK: true},
+		{name: "reset delay", status: 429, header: http.Header{"X-Ratelimit-Reset": {"7"}}, want: 7 * time.Second, wantOK: true},
+		{name: "reset when exhausted", status: 503, header: http.Header{"X-Ratelimit-Remaining": {"0"}, "X-Ratelimit-Reset": {"7"}}, want: 7 * time.Second, wantOK: true},
+		{name: "reset of a server error", status: 503, header: http.Header{"X-Ratelimit-Remaining": {"40"}, "X-Ratelimit-Reset": {"7"}}, wantOK: false},
+		{name: "reset without remaining", status: 422, header: http.Header{"X-Ratelimit-Reset": {"7"}}, wantOK: false},
+		{name: "missing", status: 429, header: http.Header{}, wantOK: false},
+		{name: "invalid", status: 429, header: http.Header{"Retry-After": {"soon"}}, wantOK: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := retryAfter(tt.status, tt.header, now)
+			if ok != tt.wantOK || got != tt.want {
+				t.Fatalf("retryAfter() = %v, %v; want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,222 @@
+package lettermint
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// TokenRotation configures ProjectsService.RotateTokenSafely.
+type TokenRotation struct {
+	// Persist stores the new token, e.g. in a secret manager, before it is
+	// used anywhere. Required.
+	Persist func(ctx context.Context, token string) error
+
+	// Rollback is called with the new token when it fails verification.
+	// Optional. The rotation has already revoked the previous token, so
+	// Rollback must not restore it; use it to undo whatever Persist did
+	// besides storing the token, e.g. to flag the stored token as unverified
+	// or to alert an operator. Without Rollback, the new token is kept as
+	// persisted.
+	Rollback func(ctx context.Context, newToken string) error
+
+	// VerifyAttempts is the number of times the new token is checked with
+	// Client.Ping before verification fails. A new token can take a moment
+	// to be accepted everywhere. Zero or negative values use 3.
+	VerifyAttempts int
+
+	// VerifyInterval is the wait between two verification attempts. Zero
+	// or negative values use DefaultVerifyInterval.
+	VerifyInterval time.Duration
+
+	// Switch makes running clients use the new token once it has been
+	// persisted and verified, e.g. by updating the value returned by their
+	// TokenSource. Optional.
+	Switch func(ctx context.Context, token string) error
+}
+
+// DefaultVerifyInterval is the default of TokenRotation.VerifyInterval.
+const DefaultVerifyInterval = time.Second
+
+// TokenRotationStep identifies a step of a token rotation.
+type TokenRotationStep string
+
+const (
+	// RotationStepRotate rotates the token through the API.
+	RotationStepRotate TokenRotationStep = "rotate"
+
+	// RotationStepPersist hands the new token to TokenRotation.Persist.
+	RotationStepPersist TokenRotationStep = "persist"
+
+	// RotationStepVerify checks the new token with Client.Ping.
+	RotationStepVerify TokenRotationStep = "verify"
+
+	// RotationStepSwitch hands the new token to TokenRotation.Switch.
+	RotationStepSwitch TokenRotationStep = "switch"
+)
+
+// TokenRotationResult reports the outcome of a token rotation.
+type TokenRotationResult struct {
+	// Project is the project as returned by the rotation.
+	Project ProjectData
+
+	// NewToken is the new sending token. It is set as soon as the API has
+	// rotated the token, even if a later step failed, so it is never lost.
+	NewToken string
+
+	// Persisted reports whether TokenRotation.Persist succeeded.
+	Persisted bool
+
+	// Verified reports whether the new token passed verification.
+	Verified bool
+
+	// RolledBack reports whether TokenRotation.Rollback succeeded after a
+	// failed verification.
+	RolledBack bool
+
+	// Switched reports whether TokenRotation.Switch succeeded.
+	Switched bool
+}
+
+// TokenRotationError is returned by ProjectsService.RotateTokenSafely when a
+// step fails.
+type TokenRotationError struct {
+	// Step is the step that failed.
+	Step TokenRotationStep
+
+	// Err is the error of the failed step.
+	Err error
+
+	// RollbackErr is the error returned by TokenRotation.Rollback, if it
+	// was called and failed.
+	RollbackErr error
+}
+
+// Error implements the error interface.
+func (e *TokenRotationError) Error() string {
+	msg := fmt.Sprintf("lettermint: token rotation failed at %s step: %v", e.Step, e.Err)
+	if e.RollbackErr != nil {
+		msg += fmt.Sprintf(" (rollback failed: %v)", e.RollbackErr)
+	}
+	return msg
+}
+
+// Unwrap returns the errors of the failed step and of the rollback.
+func (e *TokenRotationError) Unwrap() []error {
+	if e.RollbackErr != nil {
+		return []error{e.Err, e.RollbackErr}
+	}
+	return []error{e.Err}
+}
+
+// RotateTokenSafely rotates the sending token of a project and rolls the new
+// token out without downtime.
+//
+// The steps are:
+//
+//  1. Rotate the token with RotateToken.
+//  2. Store the new token with rotation.Persist.
+//  3. Verify the new token by calling Client.Ping with it, using the base
+//     URL, HTTP client and other settings of this client, up to
+//     rotation.VerifyAttempts times, rotation.VerifyInterval apart.
+//  4. Switch running clients to the new token with rotation.Switch.
+//
+// If verification fails, Switch is not called and rotation.Rollback, if
+// set, is called with the new token; the result and the error report
+// whether it succeeded. The rotation revokes the previous token and cannot
+// be undone, so Rollback cannot bring the previous token back. Without
+// Rollback, the new token is kept in the secret store: investigate the
+// error and switch to the persisted token once it works. Verification does
+// not use the client's circuit breaker, so failed checks do not count
+// against it.
+//
+// On failure, the returned error is a *TokenRotationError naming the failed
+// step, and the result reports which steps completed. The result always
+// contains the new token once the API has rotated it, so it can be stored
+// manually if persisting it failed.
+//
+// Example:
+//
+//	result, err := api.Projects.RotateTokenSafely(ctx, projectID, lettermint.TokenRotation{
+//	    Persist: func(ctx context.Context, token string) error {
+//	        return secrets.Put(ctx, "lettermint-token", token)
+//	    },
+//	    Switch: func(ctx context.Context, token string) error {
+//	        currentToken.Store(token)
+//	        return nil
+//	    },
+//	})
+func (s *ProjectsService) RotateTokenSafely(ctx context.Context, projectID string, rotation TokenRotation) (*TokenRotationResult, error) {
+	if rotation.Persist == nil {
+		return nil, fmt.Errorf("%w: TokenRotation.Persist is required", ErrInvalidRequest)
+	}
+
+	result := &TokenRotationResult{}
+	rotated, err := s.RotateToken(ctx, projectID)
+	if err != nil {
+		return result, &TokenRotationError{Step: RotationStepRotate, Err: err}
+	}
+	result.Project = rotated.Data
+	result.NewToken = rotated.NewToken
+	if rotated.NewToken == "" {
+		return result, &TokenRotationError{Step: RotationStepRotate, Err: errors.New("response did not include a new token")}
+	}
+
+	if err := rotation.Persist(ctx, rotated.NewToken); err != nil {
+		return result, &TokenRotationError{Step: RotationStepPersist, Err: err}
+	}
+	result.Persisted = true
+
+	if err := s.client.verifyToken(ctx, rotated.NewToken, rotation.VerifyAttempts, rotation.VerifyInterval); err != nil {
+		rotationErr := &TokenRotationError{Step: RotationStepVerify, Err: err}
+		if rotation.Rollback != nil {
+			if err := rotation.Rollback(ctx, rotated.NewToken); err != nil {
+				rotationErr.RollbackErr = err
+			} else {
+				result.RolledBack = true
+			}
+		}
+		return result, rotationErr
+	}
+	result.Verified = true
+
+	if rotation.Switch != nil {
+		if err := rotation.Switch(ctx, rotated.NewToken); err != nil {
+			return result, &TokenRotationError{Step: RotationStepSwitch, Err: err}
+		}
+		result.Switched = true
+	}
+	return result, nil
+}
+
+// verifyToken checks a sending token with Ping, making up to attempts
+// attempts interval apart. It bypasses the circuit breaker, which is shared
+// with the client's other requests.
+func (c *Client) verifyToken(ctx context.Context, token string, attempts int, interval time.Duration) error {
+	if attempts <= 0 {
+		attempts = 3
+	}
+	if interval <= 0 {
+		interval = DefaultVerifyInterval
+	}
+	verifier := c.withToken(token, authSchemeSending)
+	verifier.circuitBreaker = nil
+	for attempt := 1; ; attempt++ {
+		_, err := verifier.Ping(ctx)
+		if err == nil || attempt >= attempts || ctx.Err() != nil {
+			return err
+		}
+		if err := sleep(ctx, interval); err != nil {
+			return err
+		}
+	}
+}
+
+// withToken returns a copy of the client that authenticates with token.
+func (c *Client) withToken(token string, authScheme authenticationScheme) *Client {
+	clone := *c
+	clone.tokenSource = StaticTokenSource(token)
+	clone.authScheme = authScheme
+	return &clone
+}
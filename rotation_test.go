@@ -0,0 +1,217 @@
+package lettermint
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// newRotationServer returns a server that rotates the token of proj_1 to
+// "new-token" and accepts validToken from the validAfter-th ping on.
+func newRotationServer(t *testing.T, validToken string, validAfter int) *httptest.Server {
+	t.Helper()
+	pings := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/projects/proj_1/rotate-token":
+			if r.Header.Get("Authorization") != "Bearer api-token" {
+				t.Errorf("rotate Authorization = %q", r.Header.Get("Authorization"))
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"data":      map[string]any{"id": "proj_1"},
+				"new_token": "new-token",
+			})
+		case "/ping":
+			pings++
+			if r.Header.Get("x-lettermint-token") != validToken || pings < validAfter {
+				w.WriteHeader(http.StatusUnauthorized)
+				_, _ = w.Write([]byte(`{"message":"Unauthenticated."}`))
+				return
+			}
+			_, _ = w.Write([]byte("pong"))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestRotateTokenSafely(t *testing.T) {
+	server := newRotationServer(t, "new-token", 0)
+	api, _ := NewAPI("api-token", WithBaseURL(server.URL))
+
+	var steps []string
+	result, err := api.Projects.RotateTokenSafely(context.Background(), "proj_1", TokenRotation{
+		Persist: func(ctx context.Context, token string) error {
+			steps = append(steps, "persist "+token)
+			return nil
+		},
+		Switch: func(ctx context.Context, token string) error {
+			steps = append(steps, "switch "+token)
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("RotateTokenSafely() error = %v", err)
+	}
+
+	want := &TokenRotationResult{Project: ProjectData{ID: "proj_1"}, NewToken: "new-token", Persisted: true, Verified: true, Switched: true}
+	if !reflect.DeepEqual(result, want) {
+		t.Fatalf("result = %+v, want %+v", result, want)
+	}
+	if !reflect.DeepEqual(steps, []string{"persist new-token", "switch new-token"}) {
+		t.Fatalf("steps = %v", steps)
+	}
+}
+
+func TestRotateTokenSafely_VerificationRetries(t *testing.T) {
+	server := newRotationServer(t, "new-token", 3)
+	var pings []time.Time
+	record := func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if req.URL.Path == "/ping" {
+				pings = append(pings, time.Now())
+			}
+			return next.RoundTrip(req)
+		})
+	}
+	// Without a retry policy, the interval still spaces out the attempts.
+	api, _ := NewAPI("api-token", WithBaseURL(server.URL), WithMiddleware(record))
+
+	interval := 50 * time.Millisecond
+	result, err := api.Projects.RotateTokenSafely(context.Background(), "proj_1", TokenRotation{
+		Persist:        func(ctx context.Context, token string) error { return nil },
+		VerifyAttempts: 3,
+		VerifyInterval: interval,
+	})
+	if err != nil || !result.Verified {
+		t.Fatalf("RotateTokenSafely() = %+v, %v, want the token verified on the third attempt", result, err)
+	}
+	if len(pings) != 3 {
+		t.Fatalf("pings = %d, want 3", len(pings))
+	}
+	for i := 1; i < len(pings); i++ {
+		if gap := pings[i].Sub(pings[i-1]); gap < interval {
+			t.Errorf("ping %d came %v after the previous one, want at least %v", i+1, gap, interval)
+		}
+	}
+}
+
+func TestRotateTokenSafely_VerificationFails(t *testing.T) {
+	server := newRotationServer(t, "other-token", 0)
+	api, _ := NewAPI("api-token",
+		WithBaseURL(server.URL),
+		WithRetryPolicy(testRetryPolicy()),
+		WithCircuitBreaker(CircuitBreakerConfig{
+			FailureThreshold: 1,
+			CoolDown:         time.Hour,
+			IsFailure:        func(error) bool { return true },
+		}),
+	)
+
+	var persisted []string
+	switched := false
+	result, err := api.Projects.RotateTokenSafely(context.Background(), "proj_1", TokenRotation{
+		Persist: func(ctx context.Context, token string) error {
+			persisted = append(persisted, token)
+			return nil
+		},
+		Switch: func(ctx context.Context, token string) error {
+			switched = true
+			return nil
+		},
+		VerifyInterval: time.Millisecond,
+	})
+
+	var rotationErr *TokenRotationError
+	if !errors.As(err, &rotationErr) || rotationErr.Step != RotationStepVerify || !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("RotateTokenSafely() error = %v, want verify step error", err)
+	}
+	if switched {
+		t.Fatal("Switch called after failed verification")
+	}
+	if result.NewToken != "new-token" || !result.Persisted || result.Verified || result.RolledBack {
+		t.Fatalf("result = %+v", result)
+	}
+	if !reflect.DeepEqual(persisted, []string{"new-token"}) {
+		t.Fatalf("persisted = %v, want the new token to stay persisted", persisted)
+	}
+
+	// The failed checks did not open the API client's circuit.
+	if _, err := api.Projects.RotateToken(context.Background(), "proj_1"); err != nil {
+		t.Fatalf("RotateToken() error = %v, want the circuit to stay closed", err)
+	}
+}
+
+func TestRotateTokenSafely_Rollback(t *testing.T) {
+	server := newRotationServer(t, "other-token", 0)
+	api, _ := NewAPI("api-token", WithBaseURL(server.URL))
+	rotate := func(rollback func(ctx context.Context, newToken string) error) (*TokenRotationResult, error) {
+		return api.Projects.RotateTokenSafely(context.Background(), "proj_1", TokenRotation{
+			Persist:        func(ctx context.Context, token string) error { return nil },
+			Rollback:       rollback,
+			VerifyInterval: time.Millisecond,
+		})
+	}
+
+	var rolledBack []string
+	result, err := rotate(func(ctx context.Context, newToken string) error {
+		rolledBack = append(rolledBack, newToken)
+		return nil
+	})
+	var rotationErr *TokenRotationError
+	if !errors.As(err, &rotationErr) || rotationErr.Step != RotationStepVerify || rotationErr.RollbackErr != nil {
+		t.Fatalf("RotateTokenSafely() error = %v, want verify step error", err)
+	}
+	if !result.RolledBack || !reflect.DeepEqual(rolledBack, []string{"new-token"}) {
+		t.Fatalf("result = %+v, rolled back %v, want a rollback of the new token", result, rolledBack)
+	}
+
+	errRollback := errors.New("secret store unavailable")
+	result, err = rotate(func(ctx context.Context, newToken string) error { return errRollback })
+	if !errors.As(err, &rotationErr) || rotationErr.RollbackErr != errRollback || !errors.Is(err, ErrUnauthorized) || !errors.Is(err, errRollback) {
+		t.Fatalf("RotateTokenSafely() error = %v, want the verify and rollback errors", err)
+	}
+	if result.RolledBack {
+		t.Fatalf("result = %+v, want RolledBack false after a failed rollback", result)
+	}
+}
+
+func TestRotateTokenSafely_PersistFails(t *testing.T) {
+	server := newRotationServer(t, "new-token", 0)
+	api, _ := NewAPI("api-token", WithBaseURL(server.URL))
+
+	errPersist := errors.New("write failed")
+	switched := false
+	result, err := api.Projects.RotateTokenSafely(context.Background(), "proj_1", TokenRotation{
+		Persist: func(ctx context.Context, token string) error { return errPersist },
+		Switch: func(ctx context.Context, token string) error {
+			switched = true
+			return nil
+		},
+	})
+
+	var rotationErr *TokenRotationError
+	if !errors.As(err, &rotationErr) || rotationErr.Step != RotationStepPersist || !errors.Is(err, errPersist) {
+		t.Fatalf("RotateTokenSafely() error = %v, want persist step error", err)
+	}
+	if switched {
+		t.Fatal("Switch called although nothing was persisted")
+	}
+	if result.NewToken != "new-token" || result.Persisted {
+		t.Fatalf("result = %+v", result)
+	}
+}
+
+func TestRotateTokenSafely_RequiresPersist(t *testing.T) {
+	api, _ := NewAPI("api-token")
+	if _, err := api.Projects.RotateTokenSafely(context.Background(), "proj_1", TokenRotation{}); !errors.Is(err, ErrInvalidRequest) {
+		t.Fatalf("RotateTokenSafely() error = %v, want ErrInvalidRequest", err)
+	}
+}
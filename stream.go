@@ -0,0 +1,160 @@
+package lettermint
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// SourceStream returns the raw RFC 822 source of a message as a stream,
+// without buffering it in memory.
+//
+// If maxBytes is positive, reading more than maxBytes bytes fails with
+// ErrResponseTooLarge. If it is zero, the response size limit of the client
+// applies (see WithMaxResponseBytes and LimitResponseBytes), and if it is
+// negative, the size is not limited. The caller must close the returned
+// reader.
+func (s *MessagesService) SourceStream(ctx context.Context, messageID string, maxBytes int64, opts ...CallOption) (io.ReadCloser, error) {
+	return s.client.doStream(ctx, messageOperation("Messages.Source", messageID), http.MethodGet, "/messages/"+segment(messageID)+"/source", nil, maxBytes, opts)
+}
+
+// HTMLStream returns the HTML body of a message as a stream. See SourceStream.
+func (s *MessagesService) HTMLStream(ctx context.Context, messageID string, maxBytes int64, opts ...CallOption) (io.ReadCloser, error) {
+	return s.client.doStream(ctx, messageOperation("Messages.HTML", messageID), http.MethodGet, "/messages/"+segment(messageID)+"/html", nil, maxBytes, opts)
+}
+
+// TextStream returns the plain text body of a message as a stream.
+// See SourceStream.
+func (s *MessagesService) TextStream(ctx context.Context, messageID string, maxBytes int64, opts ...CallOption) (io.ReadCloser, error) {
+	return s.client.doStream(ctx, messageOperation("Messages.Text", messageID), http.MethodGet, "/messages/"+segment(messageID)+"/text", nil, maxBytes, opts)
+}
+
+// WriteSource writes the raw RFC 822 source of a message to w and returns
+// the number of bytes written.
+//
+// A message larger than maxBytes fails with ErrResponseTooLarge, see
+// SourceStream for how maxBytes is interpreted. Data written to w before the
+// limit was reached is not removed.
+func (s *MessagesService) WriteSource(ctx context.Context, messageID string, w io.Writer, maxBytes int64, opts ...CallOption) (int64, error) {
+	body, err := s.SourceStream(ctx, messageID, maxBytes, opts...)
+	if err != nil {
+		return 0, err
+	}
+	return copyStream(w, body)
+}
+
+// WriteHTML writes the HTML body of a message to w. See WriteSource.
+func (s *MessagesService) WriteHTML(ctx context.Context, messageID string, w io.Writer, maxBytes int64, opts ...CallOption) (int64, error) {
+	body, err := s.HTMLStream(ctx, messageID, maxBytes, opts...)
+	if err != nil {
+		return 0, err
+	}
+	return copyStream(w, body)
+}
+
+// WriteText writes the plain text body of a message to w. See WriteSource.
+func (s *MessagesService) WriteText(ctx context.Context, messageID string, w io.Writer, maxBytes int64, opts ...CallOption) (int64, error) {
+	body, err := s.TextStream(ctx, messageID, maxBytes, opts...)
+	if err != nil {
+		return 0, err
+	}
+	return copyStream(w, body)
+}
+
+// copyStream copies a stream to w and closes it.
+func copyStream(w io.Writer, body io.ReadCloser) (int64, error) {
+	defer body.Close()
+	return io.Copy(w, body)
+}
+
+// doStream sends a request and returns the response body unread. The
+// operation is finished as soon as the response headers are received.
+func (c *Client) doStream(ctx context.Context, op *Operation, method, path string, query map[string]string, maxBytes int64, opts []CallOption) (io.ReadCloser, error) {
+	resp, err := c.doResponse(ctx, op, method, path, query, nil, maxBytes, opts)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// doResponse sends a request and returns the successful response with an
+// unread body, which enforces the response size limit and releases the call
+// when closed. See SourceStream for how maxBytes is interpreted.
+func (c *Client) doResponse(ctx context.Context, op *Operation, method, path string, query map[string]string, body []byte, maxBytes int64, opts []CallOption) (*http.Response, error) {
+	ctx, header, cancel := applyCallOptions(ctx, opts)
+
+	var resp *http.Response
+	err := c.observe(ctx, op, method, path, func(ctx context.Context) error {
+		switch {
+		case maxBytes == 0:
+			maxBytes = c.responseLimit(ctx)
+		case maxBytes < 0:
+			maxBytes = 0
+		}
+
+		var err error
+		resp, err = c.do(ctx, op, method, path, query, bytesBody(body), header)
+		if err != nil {
+			return err
+		}
+		if maxBytes > 0 && resp.ContentLength > maxBytes {
+			resp.Body.Close()
+			return responseTooLarge(maxBytes)
+		}
+		resp.Body = &responseReader{ctx: ctx, body: resp.Body, limit: maxBytes, remaining: maxBytes, cancel: cancel}
+		return nil
+	})
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	return resp, nil
+}
+
+// responseReader reads a response body, enforcing an optional size limit
+// and classifying read errors like the rest of the client.
+type responseReader struct {
+	ctx       context.Context
+	body      io.ReadCloser
+	limit     int64 // no limit if not positive
+	remaining int64
+	cancel    context.CancelFunc
+}
+
+func (r *responseReader) Read(p []byte) (int, error) {
+	if r.limit > 0 {
+		if r.remaining < 0 {
+			return 0, responseTooLarge(r.limit)
+		}
+		// Read one byte more than allowed to detect bodies exceeding the limit.
+		if int64(len(p)) > r.remaining+1 {
+			p = p[:r.remaining+1]
+		}
+	}
+
+	n, err := r.body.Read(p)
+	if r.limit > 0 {
+		r.remaining -= int64(n)
+		if r.remaining < 0 {
+			return n + int(r.remaining), responseTooLarge(r.limit)
+		}
+	}
+	if err != nil && !errors.Is(err, io.EOF) {
+		err = readError(r.ctx, err)
+	}
+	return n, err
+}
+
+func (r *responseReader) Close() error {
+	err := r.body.Close()
+	if r.cancel != nil {
+		r.cancel()
+	}
+	return err
+}
+
+func responseTooLarge(limit int64) error {
+	return fmt.Errorf("%w: limit is %d bytes", ErrResponseTooLarge, limit)
+}
@@ -0,0 +1,91 @@
+package lettermint
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newStreamServer(t *testing.T, body string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/messages/msg_1/source", "/messages/msg_1/html":
+			_, _ = io.WriteString(w, body)
+		case "/messages/msg_1/text":
+			// Flush before writing to send the body without Content-Length.
+			w.(http.Flusher).Flush()
+			_, _ = io.WriteString(w, body)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message":"Not found"}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestMessagesService_SourceStream(t *testing.T) {
+	source := "From: sender@example.com\r\n\r\n" + strings.Repeat("x", 64<<10)
+	server := newStreamServer(t, source)
+	api, _ := NewAPI("api-token", WithBaseURL(server.URL))
+
+	body, err := api.Messages.SourceStream(context.Background(), "msg_1", 0)
+	if err != nil {
+		t.Fatalf("SourceStream() error = %v", err)
+	}
+	defer body.Close()
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+	if string(data) != source {
+		t.Fatalf("SourceStream() returned %d bytes, want %d", len(data), len(source))
+	}
+}
+
+func TestMessagesService_StreamMaxBytes(t *testing.T) {
+	server := newStreamServer(t, strings.Repeat("x", 1000))
+	api, _ := NewAPI("api-token", WithBaseURL(server.URL))
+	ctx := context.Background()
+
+	// Rejected up front from Content-Length.
+	if _, err := api.Messages.HTMLStream(ctx, "msg_1", 999); !errors.Is(err, ErrResponseTooLarge) {
+		t.Fatalf("HTMLStream() error = %v, want ErrResponseTooLarge", err)
+	}
+
+	// Detected while reading a body without Content-Length.
+	var buf bytes.Buffer
+	n, err := api.Messages.WriteText(ctx, "msg_1", &buf, 999)
+	if !errors.Is(err, ErrResponseTooLarge) {
+		t.Fatalf("WriteText() error = %v, want ErrResponseTooLarge", err)
+	}
+	if n > 999 || int64(buf.Len()) != n {
+		t.Fatalf("WriteText() wrote %d bytes (buffer %d), want at most 999", n, buf.Len())
+	}
+
+	buf.Reset()
+	n, err = api.Messages.WriteText(ctx, "msg_1", &buf, 1000)
+	if err != nil || n != 1000 || buf.Len() != 1000 {
+		t.Fatalf("WriteText() = %d, %v, want 1000 bytes at the limit", n, err)
+	}
+}
+
+func TestMessagesService_WriteSourceError(t *testing.T) {
+	server := newStreamServer(t, "")
+	api, _ := NewAPI("api-token", WithBaseURL(server.URL))
+
+	var buf bytes.Buffer
+	if _, err := api.Messages.WriteSource(context.Background(), "missing", &buf, 0); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("WriteSource() error = %v, want ErrNotFound", err)
+	}
+	if buf.Len() != 0 {
+		t.Fatalf("WriteSource() wrote %q for an error response", buf.String())
+	}
+}
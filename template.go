@@ -0,0 +1,248 @@
+package lettermint
+
+import (
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	texttemplate "text/template"
+)
+
+// TemplateError is returned when an email template cannot be rendered.
+type TemplateError struct {
+	// Field is the part of the email rendered by the template: "subject",
+	// "html" or "text".
+	Field string
+
+	// Template is the name of the template.
+	Template string
+
+	// Err is the underlying error.
+	Err error
+}
+
+func (e *TemplateError) Error() string {
+	return fmt.Sprintf("lettermint: %s template %q: %v", e.Field, e.Template, e.Err)
+}
+
+func (e *TemplateError) Unwrap() error {
+	return e.Err
+}
+
+// HTMLTemplate sets the HTML body to the result of executing tmpl with data.
+//
+// The template is executed immediately; an error is returned by Send as a
+// *TemplateError.
+func (b *EmailBuilder) HTMLTemplate(tmpl *htmltemplate.Template, data interface{}) *EmailBuilder {
+	if tmpl == nil {
+		b.recordError(&TemplateError{Field: "html", Err: errors.New("template is nil")})
+		return b
+	}
+	if html, ok := b.render("html", tmpl, data); ok {
+		b.payload.HTML = html
+	}
+	return b
+}
+
+// TextTemplate sets the plain text body to the result of executing tmpl
+// with data. See HTMLTemplate.
+func (b *EmailBuilder) TextTemplate(tmpl *texttemplate.Template, data interface{}) *EmailBuilder {
+	if tmpl == nil {
+		b.recordError(&TemplateError{Field: "text", Err: errors.New("template is nil")})
+		return b
+	}
+	if text, ok := b.render("text", tmpl, data); ok {
+		b.payload.Text = text
+	}
+	return b
+}
+
+// SubjectTemplate sets the subject to the result of executing tmpl with
+// data. Line breaks and repeated whitespace in the result are collapsed to
+// single spaces. See HTMLTemplate.
+func (b *EmailBuilder) SubjectTemplate(tmpl *texttemplate.Template, data interface{}) *EmailBuilder {
+	if tmpl == nil {
+		b.recordError(&TemplateError{Field: "subject", Err: errors.New("template is nil")})
+		return b
+	}
+	if subject, ok := b.render("subject", tmpl, data); ok {
+		b.payload.Subject = strings.Join(strings.Fields(subject), " ")
+	}
+	return b
+}
+
+// Template sets the subject, HTML body and text body from the templates
+// registered under name, as far as they exist. See Templates.
+//
+// Example:
+//
+//	templates, err := lettermint.LoadTemplates(emailTemplates, nil)
+//
+//	_, err = client.Email(ctx).
+//	    From("shop@example.com").
+//	    To(order.Email).
+//	    Template(templates, "orders/shipped", order).
+//	    Send()
+func (b *EmailBuilder) Template(templates *Templates, name string, data interface{}) *EmailBuilder {
+	subject, html, text := templates.lookup(name)
+	if subject == nil && html == nil && text == nil {
+		b.recordError(&TemplateError{Field: "html", Template: name, Err: errors.New("template not found")})
+		return b
+	}
+	if subject != nil {
+		b.SubjectTemplate(subject, data)
+	}
+	if html != nil {
+		b.HTMLTemplate(html, data)
+	}
+	if text != nil {
+		b.TextTemplate(text, data)
+	}
+	return b
+}
+
+// templateExecutor is implemented by html/template and text/template.
+type templateExecutor interface {
+	Execute(w io.Writer, data interface{}) error
+	Name() string
+}
+
+// render executes tmpl, recording an error for field if it fails.
+func (b *EmailBuilder) render(field string, tmpl templateExecutor, data interface{}) (string, bool) {
+	var buf strings.Builder
+	if err := tmpl.Execute(&buf, data); err != nil {
+		b.recordError(&TemplateError{Field: field, Template: tmpl.Name(), Err: err})
+		return "", false
+	}
+	return buf.String(), true
+}
+
+// Templates is a registry of email templates loaded from a file system with
+// LoadTemplates. It is safe for concurrent use.
+type Templates struct {
+	subject map[string]*texttemplate.Template
+	html    map[string]*htmltemplate.Template
+	text    map[string]*texttemplate.Template
+}
+
+// LoadTemplates loads the email templates of fsys.
+//
+// Every email is named after its files without the extension:
+// "welcome.html" is the HTML body, "welcome.txt" the text body and
+// "welcome.subject.txt" the subject of the email "welcome". Files in
+// subdirectories are named with their path, e.g. "orders/shipped".
+//
+// Files in the "layouts" and "partials" directories are shared: they are
+// parsed together with every email template of the same kind (.html or
+// .txt), so an email can use their named templates:
+//
+//	{{/* layouts/base.html */}}
+//	{{define "base"}}<html><body>{{block "content" .}}{{end}}</body></html>{{end}}
+//
+//	{{/* welcome.html */}}
+//	{{template "base" .}}
+//	{{define "content"}}<p>Welcome, {{.Name}}!</p>{{end}}
+//
+// HTML files are parsed with html/template, text files with text/template.
+// funcs are made available to all templates and may be nil. Use fs.Sub to
+// load templates from a subdirectory of an embed.FS.
+func LoadTemplates(fsys fs.FS, funcs map[string]interface{}) (*Templates, error) {
+	htmlShared := htmltemplate.New("").Funcs(funcs)
+	textShared := texttemplate.New("").Funcs(funcs)
+	var emails []string
+
+	err := fs.WalkDir(fsys, ".", func(name string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		ext := path.Ext(name)
+		if ext != ".html" && ext != ".txt" {
+			return nil
+		}
+		if !strings.HasPrefix(name, "layouts/") && !strings.HasPrefix(name, "partials/") {
+			emails = append(emails, name)
+			return nil
+		}
+
+		content, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+		if ext == ".html" {
+			_, err = htmlShared.New(name).Parse(string(content))
+		} else {
+			_, err = textShared.New(name).Parse(string(content))
+		}
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load templates: %w", err)
+	}
+
+	templates := &Templates{
+		subject: map[string]*texttemplate.Template{},
+		html:    map[string]*htmltemplate.Template{},
+		text:    map[string]*texttemplate.Template{},
+	}
+	for _, file := range emails {
+		content, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load templates: %w", err)
+		}
+
+		switch name := strings.TrimSuffix(file, path.Ext(file)); {
+		case path.Ext(file) == ".html":
+			set, err := htmlShared.Clone()
+			if err == nil {
+				templates.html[name], err = set.New(file).Parse(string(content))
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to load templates: %w", err)
+			}
+		default:
+			set, err := textShared.Clone()
+			if err == nil {
+				if subject := strings.TrimSuffix(name, ".subject"); subject != name {
+					templates.subject[subject], err = set.New(file).Parse(string(content))
+				} else {
+					templates.text[name], err = set.New(file).Parse(string(content))
+				}
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to load templates: %w", err)
+			}
+		}
+	}
+	return templates, nil
+}
+
+// Names returns the sorted names of the emails in the registry.
+func (t *Templates) Names() []string {
+	seen := map[string]bool{}
+	for name := range t.subject {
+		seen[name] = true
+	}
+	for name := range t.html {
+		seen[name] = true
+	}
+	for name := range t.text {
+		seen[name] = true
+	}
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (t *Templates) lookup(name string) (subject *texttemplate.Template, html *htmltemplate.Template, text *texttemplate.Template) {
+	if t == nil {
+		return nil, nil, nil
+	}
+	return t.subject[name], t.html[name], t.text[name]
+}
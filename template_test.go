@@ -0,0 +1,111 @@
+package lettermint
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	htmltemplate "html/template"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+	texttemplate "text/template"
+)
+
+var testTemplates = fstest.MapFS{
+	"layouts/base.html":    {Data: []byte(`{{define "base"}}<html><body>{{block "content" .}}{{end}}{{template "footer" .}}</body></html>{{end}}`)},
+	"layouts/base.txt":     {Data: []byte(`{{define "base"}}{{block "content" .}}{{end}}` + "\n-- \n" + `{{template "signature" .}}{{end}}`)},
+	"partials/footer.html": {Data: []byte(`{{define "footer"}}<p>{{shout "Acme"}}</p>{{end}}`)},
+	"partials/sig.txt":     {Data: []byte(`{{define "signature"}}{{shout "Acme"}}{{end}}`)},
+	"welcome.html":         {Data: []byte(`{{template "base" .}}{{define "content"}}<p>Welcome, {{.Name}}!</p>{{end}}`)},
+	"welcome.txt":          {Data: []byte(`{{template "base" .}}{{define "content"}}Welcome, {{.Name}}!{{end}}`)},
+	"welcome.subject.txt":  {Data: []byte("Welcome,\n  {{.Name}}\n")},
+	"orders/shipped.html":  {Data: []byte(`{{template "base" .}}{{define "content"}}<p>Order {{.Order}} shipped</p>{{end}}`)},
+	"README.md":            {Data: []byte("not a template")},
+}
+
+func TestLoadTemplates(t *testing.T) {
+	templates, err := LoadTemplates(testTemplates, map[string]interface{}{"shout": strings.ToUpper})
+	if err != nil {
+		t.Fatalf("LoadTemplates() error = %v", err)
+	}
+	if names := templates.Names(); !reflect.DeepEqual(names, []string{"orders/shipped", "welcome"}) {
+		t.Fatalf("Names() = %v", names)
+	}
+
+	var got emailPayload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		_, _ = w.Write([]byte(`{"message_id":"msg_1","status":"pending"}`))
+	}))
+	defer server.Close()
+
+	client, _ := New("test-token", WithBaseURL(server.URL))
+	_, err = client.Email(context.Background()).
+		From("sender@example.com").
+		To("recipient@example.com").
+		Template(templates, "welcome", map[string]string{"Name": "<Jane>"}).
+		Send()
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	if got.Subject != "Welcome, <Jane>" {
+		t.Errorf("Subject = %q", got.Subject)
+	}
+	if want := "<html><body><p>Welcome, &lt;Jane&gt;!</p><p>ACME</p></body></html>"; got.HTML != want {
+		t.Errorf("HTML = %q, want %q", got.HTML, want)
+	}
+	if want := "Welcome, <Jane>!\n-- \nACME"; got.Text != want {
+		t.Errorf("Text = %q, want %q", got.Text, want)
+	}
+}
+
+func TestLoadTemplates_ParseError(t *testing.T) {
+	fsys := fstest.MapFS{"broken.html": {Data: []byte(`{{if}}`)}}
+	if _, err := LoadTemplates(fsys, nil); err == nil {
+		t.Fatal("LoadTemplates() error = nil for an invalid template")
+	}
+}
+
+func TestEmailBuilder_TemplateErrors(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+	}))
+	defer server.Close()
+
+	client, _ := New("test-token", WithBaseURL(server.URL))
+	email := func() *EmailBuilder {
+		return client.Email(context.Background()).
+			From("sender@example.com").
+			To("recipient@example.com").
+			Subject("Hello")
+	}
+
+	failing := htmltemplate.Must(htmltemplate.New("receipt").Parse(`{{.Missing.Field}}`))
+	text := texttemplate.Must(texttemplate.New("receipt.txt").Option("missingkey=error").Parse(`{{.total}}`))
+	templates, _ := LoadTemplates(testTemplates, map[string]interface{}{"shout": strings.ToUpper})
+
+	tests := []struct {
+		builder  *EmailBuilder
+		field    string
+		template string
+	}{
+		{email().HTMLTemplate(failing, struct{ Missing *struct{ Field string } }{}), "html", "receipt"},
+		{email().HTML("<p>Hi</p>").TextTemplate(text, map[string]string{}), "text", "receipt.txt"},
+		{email().Template(templates, "missing", nil), "html", "missing"},
+	}
+	for _, tt := range tests {
+		_, err := tt.builder.Send()
+		var templateErr *TemplateError
+		if !errors.Is(err, ErrInvalidRequest) || !errors.As(err, &templateErr) || templateErr.Field != tt.field || templateErr.Template != tt.template {
+			t.Errorf("Send() error = %v, want %s template %q error", err, tt.field, tt.template)
+		}
+	}
+	if requests != 0 {
+		t.Fatalf("requests = %d, want none", requests)
+	}
+}
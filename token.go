@@ -0,0 +1,103 @@
+package lettermint
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TokenSource supplies the API token used to authenticate requests.
+//
+// The client asks its token source for a token before every request, so a
+// token source can pick up a rotated token without recreating the client.
+// Implementations must be safe for concurrent use.
+type TokenSource interface {
+	// Token returns the current API token.
+	Token(ctx context.Context) (string, error)
+}
+
+// TokenSourceFunc adapts a function to a TokenSource.
+//
+// Use it to fetch the token from a secret manager. The function is called
+// before every request and should cache the token itself if fetching it is
+// expensive.
+type TokenSourceFunc func(ctx context.Context) (string, error)
+
+// Token calls f(ctx).
+func (f TokenSourceFunc) Token(ctx context.Context) (string, error) {
+	return f(ctx)
+}
+
+type staticTokenSource string
+
+// StaticTokenSource returns a TokenSource that always returns token.
+//
+// New and NewAPI use a static token source for the given token.
+func StaticTokenSource(token string) TokenSource {
+	return staticTokenSource(token)
+}
+
+func (s staticTokenSource) Token(context.Context) (string, error) {
+	return string(s), nil
+}
+
+// FileTokenSource returns a TokenSource that reads the token from a file,
+// such as a mounted Kubernetes secret.
+//
+// The file is read again whenever its modification time or size changes,
+// so writing a new token to the file rotates the token of all clients using
+// the source. Surrounding whitespace is ignored. Replace the file atomically
+// (write a temporary file and rename it) to avoid reading a partial token.
+func FileTokenSource(path string) TokenSource {
+	return &fileTokenSource{path: path}
+}
+
+type fileTokenSource struct {
+	path string
+
+	mu      sync.Mutex
+	token   string
+	modTime time.Time
+	size    int64
+}
+
+func (s *fileTokenSource) Token(context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return "", fmt.Errorf("lettermint: failed to read token file: %w", err)
+	}
+	if s.token != "" && info.ModTime().Equal(s.modTime) && info.Size() == s.size {
+		return s.token, nil
+	}
+
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return "", fmt.Errorf("lettermint: failed to read token file: %w", err)
+	}
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return "", fmt.Errorf("%w: token file %s is empty", ErrInvalidAPIToken, s.path)
+	}
+	s.token = token
+	s.modTime = info.ModTime()
+	s.size = info.Size()
+	return token, nil
+}
+
+// token returns the token for a request.
+func (c *Client) token(ctx context.Context) (string, error) {
+	token, err := c.tokenSource.Token(ctx)
+	if err != nil {
+		return "", err
+	}
+	if token == "" {
+		return "", ErrInvalidAPIToken
+	}
+	return token, nil
+}
@@ -0,0 +1,106 @@
+package lettermint
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestNewWithTokenSource_FetchesTokenPerRequest(t *testing.T) {
+	var tokens []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tokens = append(tokens, r.Header.Get("x-lettermint-token"))
+		_, _ = w.Write([]byte("pong"))
+	}))
+	defer server.Close()
+
+	current := "token-1"
+	client, err := NewWithTokenSource(TokenSourceFunc(func(ctx context.Context) (string, error) {
+		return current, nil
+	}), WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("NewWithTokenSource() error = %v", err)
+	}
+
+	_, _ = client.Ping(context.Background())
+	current = "token-2"
+	_, _ = client.Ping(context.Background())
+
+	if len(tokens) != 2 || tokens[0] != "token-1" || tokens[1] != "token-2" {
+		t.Fatalf("tokens = %v, want [token-1 token-2]", tokens)
+	}
+}
+
+func TestNewAPIWithTokenSource_Errors(t *testing.T) {
+	if _, err := NewAPIWithTokenSource(nil); !errors.Is(err, ErrInvalidAPIToken) {
+		t.Fatalf("NewAPIWithTokenSource(nil) error = %v, want ErrInvalidAPIToken", err)
+	}
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+	}))
+	defer server.Close()
+
+	errVault := errors.New("vault unavailable")
+	api, _ := NewAPIWithTokenSource(TokenSourceFunc(func(ctx context.Context) (string, error) {
+		return "", errVault
+	}), WithBaseURL(server.URL), WithRetryPolicy(testRetryPolicy()))
+	if _, err := api.Ping(context.Background()); !errors.Is(err, errVault) {
+		t.Fatalf("Ping() error = %v, want token source error", err)
+	}
+
+	api, _ = NewAPIWithTokenSource(StaticTokenSource(""), WithBaseURL(server.URL))
+	if _, err := api.Ping(context.Background()); !errors.Is(err, ErrInvalidAPIToken) {
+		t.Fatalf("Ping() error = %v, want ErrInvalidAPIToken", err)
+	}
+	if requests != 0 {
+		t.Fatalf("server received %d requests, want 0", requests)
+	}
+}
+
+func TestFileTokenSource(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(path, []byte("token-1\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	source := FileTokenSource(path)
+
+	token, err := source.Token(context.Background())
+	if err != nil || token != "token-1" {
+		t.Fatalf("Token() = %q, %v, want token-1", token, err)
+	}
+
+	// Rotate the token with an atomic rename, as secret managers do.
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte("token-22"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(tmp, time.Now(), time.Now().Add(time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		t.Fatal(err)
+	}
+
+	token, err = source.Token(context.Background())
+	if err != nil || token != "token-22" {
+		t.Fatalf("Token() after rotation = %q, %v, want token-22", token, err)
+	}
+
+	if err := os.WriteFile(path, []byte("  \n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := source.Token(context.Background()); !errors.Is(err, ErrInvalidAPIToken) {
+		t.Fatalf("Token() for empty file error = %v, want ErrInvalidAPIToken", err)
+	}
+
+	if _, err := FileTokenSource(filepath.Join(t.TempDir(), "missing")).Token(context.Background()); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("Token() for missing file error = %v, want os.ErrNotExist", err)
+	}
+}
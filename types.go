@@ -20,9 +20,16 @@ type Attachment struct {
 	// Content is the base64-encoded content of the attachment.
 	Content string `json:"content"`
 
+	// ContentType is the MIME type of the attachment (optional).
+	ContentType string `json:"content_type,omitempty"`
+
 	// ContentID is the Content-ID for inline attachments (optional).
 	// Used for embedding images in HTML via cid: references.
 	ContentID string `json:"content_id,omitempty"`
+
+	// source provides the unencoded content of attachments added with
+	// AttachReader, AttachFile or AttachFS.
+	source *attachmentSource
 }
 
 // emailPayload is the internal structure sent to the API.